@@ -18,10 +18,13 @@ package ansiterm
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 	"unicode/utf8"
@@ -32,9 +35,31 @@ import (
 type AnsiTerminal struct {
 	ioDevice io.ReadWriteCloser
 	*bufio.ReadWriter
-	columns     int
-	rows        int
-	Cp437toUtf8 bool
+	lastKey rune
+	// settings that are updated by the protocol handlers while the session is running
+	settingsLock sync.RWMutex
+	columns      int
+	rows         int
+	termTypes    []string
+	capabilities Capability
+	cp437toUtf8  bool
+	// how long WaitKey waits for the player
+	KeyTimeout time.Duration
+	// keys are read by a single goroutine, so a read that times out doesn't compete with the next one
+	keys       chan *ReadResponse
+	pendingKey *ReadResponse
+	readerOnce sync.Once
+	closed     chan struct{}
+	closeOnce  sync.Once
+	// messages from other players, shown while we wait for a key
+	notifications chan string
+	// an event on this channel aborts the input we are waiting for
+	interrupts <-chan struct{}
+	// what was printed on the current line, so we can draw it again after a notification
+	currentLine   []byte
+	lineColor     string
+	lastColor     string
+	lineTruncated bool
 }
 
 type AnsiColor int
@@ -58,24 +83,69 @@ const (
 	InputUpfirst  InputMode = 4
 )
 
+const (
+	defaultKeyTimeout = 30 * time.Second
+	// notifications that are waiting to be shown. If a player doesn't read them, new ones are dropped.
+	maxNotifications = 32
+	// we don't redraw lines that are longer than this
+	maxLineLength = 1024
+)
+
+var sgrSequence = regexp.MustCompile(`\x1B\[[0-9;]*m`)
+
+// ErrInterrupted is returned by the input routines when the user interrupts the input (eg: telnet Interrupt Process).
+var ErrInterrupted = errors.New("input interrupted")
+
+var errClosed = errors.New("terminal closed")
+
 func CreateAnsiTerminal(device io.ReadWriteCloser) *AnsiTerminal {
 	term := AnsiTerminal{
 		ioDevice:   device,
 		ReadWriter: bufio.NewReadWriter(bufio.NewReader(device), bufio.NewWriter(device)),
 		// this is pretty standard in case we don't receive any updates on the size
-		columns: 80,
-		rows:    24,
+		columns:       80,
+		rows:          24,
+		KeyTimeout:    defaultKeyTimeout,
+		keys:          make(chan *ReadResponse, 16),
+		closed:        make(chan struct{}),
+		notifications: make(chan string, maxNotifications),
 	}
 	return &term
 }
 
-func (t *AnsiTerminal) Close() (err error) {
+// SetInterrupts sets the channel on which interrupts from the client are received. The input we are waiting for is
+// aborted with ErrInterrupted when an event arrives.
+func (t *AnsiTerminal) SetInterrupts(interrupts <-chan struct{}) {
+	t.interrupts = interrupts
+}
+
+// Close sends the output that is still buffered, and closes the connection. Only call this from the goroutine that
+// writes to the terminal, other goroutines use Hangup.
+func (t *AnsiTerminal) Close() error {
 	t.Flush()
-	return t.ioDevice.Close()
+	return t.closeDevice()
+}
+
+// Hangup closes the connection without touching the output buffer, so it can be called from any goroutine. Writes
+// that are blocked on a dead connection fail right away, if the connection supports deadlines.
+func (t *AnsiTerminal) Hangup() error {
+	if device, ok := t.ioDevice.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		_ = device.SetWriteDeadline(time.Now())
+	}
+	return t.closeDevice()
+}
+
+func (t *AnsiTerminal) closeDevice() (err error) {
+	t.closeOnce.Do(func() {
+		close(t.closed)
+		err = t.ioDevice.Close()
+	})
+	return
 }
 
 func (t *AnsiTerminal) WriteText(data []byte) (totalWritten int, err error) {
-	if t.Cp437toUtf8 {
+	t.trackLine(data)
+	if t.Cp437toUtf8() {
 		result, err := charmap.CodePage437.NewDecoder().Bytes(data)
 		if err == nil {
 			totalWritten, err = t.Write(result)
@@ -86,7 +156,94 @@ func (t *AnsiTerminal) WriteText(data []byte) (totalWritten int, err error) {
 	return
 }
 
+// WriteMessage writes a message to the terminal from another goroutine (eg: server notifications). It bypasses the
+// output buffer of the session, and writes everything at once.
+func (t *AnsiTerminal) WriteMessage(message string) error {
+	data := []byte(strings.NewReplacer("\r\n", "\r\n", "\n", "\r\n").Replace(message))
+	if t.Cp437toUtf8() {
+		result, err := charmap.CodePage437.NewDecoder().Bytes(data)
+		if err != nil {
+			return err
+		}
+		data = result
+	}
+	_, err := t.ioDevice.Write(data)
+	return err
+}
+
+// Notify queues a message for the player. It can be called from any goroutine. The message is shown the next time the
+// session waits for a key, on its own line above the line the player is working on. Returns false if the queue is full.
+func (t *AnsiTerminal) Notify(message string) bool {
+	select {
+	case t.notifications <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// remembers the text on the line the cursor is on, and the color it started with.
+func (t *AnsiTerminal) trackLine(data []byte) {
+	// clearing the screen also clears our line, we don't want to replay that
+	if i := strings.LastIndex(string(data), "\x1B[2J"); i >= 0 {
+		data = data[i+4:]
+		t.currentLine = t.currentLine[:0]
+		t.lineTruncated = false
+	}
+	if i := strings.LastIndexByte(string(data), '\n'); i >= 0 {
+		if colors := sgrSequence.FindAll(data[:i], -1); len(colors) > 0 {
+			t.lastColor = string(colors[len(colors)-1])
+		}
+		t.lineColor = t.lastColor
+		data = data[i+1:]
+		t.currentLine = t.currentLine[:0]
+		t.lineTruncated = false
+	}
+	if colors := sgrSequence.FindAll(data, -1); len(colors) > 0 {
+		t.lastColor = string(colors[len(colors)-1])
+	}
+	if len(t.currentLine)+len(data) > maxLineLength {
+		t.lineTruncated = true
+		return
+	}
+	t.currentLine = append(t.currentLine, data...)
+}
+
+// shows a notification above the current line, and draws the line again. This includes an input field and what
+// has been typed in it so far, so the player can just continue typing.
+func (t *AnsiTerminal) showNotification(message string) {
+	line := string(t.currentLine)
+	color := t.lineColor
+	redraw := !t.lineTruncated
+	message = strings.TrimSuffix(message, "\n") + "\x1B[0m\n"
+	if redraw {
+		t.Print("\r\x1B[0m\x1B[K" + message)
+		t.WriteText([]byte(color + line))
+		// we're on the same line as before
+		t.currentLine = append(t.currentLine[:0], line...)
+		t.lineColor = color
+	} else {
+		t.Print("\x1B[0m\n" + message)
+	}
+	_ = t.Flush()
+}
+
+// SetCp437toUtf8 sets whether our CP437 output is converted to UTF-8 for the client.
+func (t *AnsiTerminal) SetCp437toUtf8(convert bool) {
+	t.settingsLock.Lock()
+	defer t.settingsLock.Unlock()
+	t.cp437toUtf8 = convert
+}
+
+func (t *AnsiTerminal) Cp437toUtf8() bool {
+	t.settingsLock.RLock()
+	defer t.settingsLock.RUnlock()
+	return t.cp437toUtf8
+}
+
 func (t *AnsiTerminal) ResizeTerminal(w int, h int) {
+	t.settingsLock.Lock()
+	defer t.settingsLock.Unlock()
 	if w > 0 {
 		t.columns = w
 	}
@@ -96,6 +253,8 @@ func (t *AnsiTerminal) ResizeTerminal(w int, h int) {
 }
 
 func (t *AnsiTerminal) GetTerminalSize() (columns int, rows int) {
+	t.settingsLock.RLock()
+	defer t.settingsLock.RUnlock()
 	return t.columns, t.rows
 }
 
@@ -201,115 +360,111 @@ func (t *AnsiTerminal) DisplayMenuItem(id rune, description string) {
 // Input routines
 
 type ReadResponse struct {
-	data []byte
-	err  error
+	key rune
+	err error
 }
 
-func (t *AnsiTerminal) WaitKey(ignoreCase bool) (r rune, err error) {
-	// wait for key that is permitted and return. If key is character, it is converted to uppercase.
-	dataChannel := make(chan *ReadResponse)
-	found := false
+// reads a single key. Keys that were received together (pasted text, or clients in line mode) stay in the buffer
+// for the next read. A LF or NUL right after CR is skipped, so the enter key is only reported once.
+func (t *AnsiTerminal) readKey() (r rune, err error) {
+	for {
+		r, _, err = t.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		previous := t.lastKey
+		t.lastKey = r
+		if previous == '\r' && (r == '\n' || r == 0) {
+			continue
+		}
+		return r, nil
+	}
+}
 
-	for !found {
+// starts the goroutine that reads the keys. It stops when the connection fails or the terminal is closed.
+func (t *AnsiTerminal) startReader() {
+	t.readerOnce.Do(func() {
 		go func() {
-			buffer := make([]byte, 256)
-			countRead, err := t.Read(buffer)
-			response := &ReadResponse{data: buffer[:countRead], err: err}
-			dataChannel <- response
-		}()
-
-		select {
-		case response := <-dataChannel:
-			err = response.err
-			if len(response.data) > 0 {
-				for _, value := range string(response.data) {
-					if unicode.IsLower(value) && ignoreCase {
-						r = unicode.ToUpper(value)
-					} else {
-						r = value
-					}
-					found = true
-					break
+			for {
+				key, err := t.readKey()
+				select {
+				case t.keys <- &ReadResponse{key: key, err: err}:
+				case <-t.closed:
+					return
+				}
+				if err != nil {
+					return
 				}
 			}
-			if response.err != nil {
-				return
-			}
+		}()
+	})
+}
 
-		case <-time.After(30 * time.Second):
-			return 0, fmt.Errorf("Read time-out after 30 seconds")
+func (t *AnsiTerminal) WaitKey(ignoreCase bool) (r rune, err error) {
+	// wait for key that is permitted and return. If key is character, it is converted to uppercase.
+	t.startReader()
+	response := t.pendingKey
+	t.pendingKey = nil
+	timeout := time.NewTimer(t.KeyTimeout)
+	defer timeout.Stop()
+	for response == nil {
+		select {
+		case response = <-t.keys:
+		case message := <-t.notifications:
+			t.showNotification(message)
+		case <-t.interrupts:
+			return 0, ErrInterrupted
+		case <-t.closed:
+			// hung up, the reader can stop before it passes on the read error
+			return 0, errClosed
+		case <-timeout.C:
+			return 0, fmt.Errorf("Read time-out after %s", t.KeyTimeout)
 		}
-
+	}
+	if response.err != nil {
+		// keep the error, the next read fails too
+		t.pendingKey = response
+		return 0, response.err
+	}
+	if unicode.IsLower(response.key) && ignoreCase {
+		r = unicode.ToUpper(response.key)
+	} else {
+		r = response.key
 	}
 	return
 }
 
 func (t *AnsiTerminal) WaitKeys(allowed string, ignoreCase bool) (r rune, err error) {
 	// wait for key that is permitted and return. If key is character, it is converted to uppercase.
-	dataChannel := make(chan *ReadResponse)
-	found := false
-
-	for !found {
-		go func() {
-			buffer := make([]byte, 256)
-			countRead, err := t.Read(buffer)
-			response := &ReadResponse{data: buffer[:countRead], err: err}
-			dataChannel <- response
-		}()
-
-		select {
-		case response := <-dataChannel:
-			err = response.err
-			if len(response.data) > 0 {
-				for _, value := range string(response.data) {
-					var current rune
-					if unicode.IsLower(value) && ignoreCase {
-						current = unicode.ToUpper(value)
-					} else {
-						current = value
-					}
-					for _, allowedRune := range allowed {
-						if unicode.IsLower(allowedRune) && ignoreCase {
-							allowedRune = unicode.ToUpper(allowedRune)
-						}
-						if current == allowedRune {
-							found = true
-							r = current
-							break
-						}
-					}
-				}
+	for {
+		var current rune
+		current, err = t.WaitKey(ignoreCase)
+		if err != nil {
+			return
+		}
+		for _, allowedRune := range allowed {
+			if unicode.IsLower(allowedRune) && ignoreCase {
+				allowedRune = unicode.ToUpper(allowedRune)
 			}
-			if response.err != nil {
-				return
+			if current == allowedRune {
+				return current, nil
 			}
-
-		case <-time.After(30 * time.Second):
-			return 0, fmt.Errorf("Read time-out after 30 seconds")
 		}
-
 	}
-	return
 }
 
 func (t *AnsiTerminal) HasIncomingData() (result bool) {
-	resultChannel := make(chan []byte)
-
-	go func() {
-		response, _ := t.Peek(1)
-		resultChannel <- response
-	}()
-
+	if t.pendingKey != nil {
+		return t.pendingKey.err == nil
+	}
+	t.startReader()
 	select {
-	case response := <-resultChannel:
-		// check if actually contains data
-		if len(response) > 0 {
-			result = true
-		} else {
-			result = false
-		}
+	case response := <-t.keys:
+		// keep it for the next WaitKey
+		t.pendingKey = response
+		result = response.err == nil
 
-	// since Peek blocks if no data is present, we use a timer
+	// the reader blocks if no data is present, so we use a timer
 	case <-time.After(200 * time.Millisecond):
 		result = false
 	}
@@ -317,6 +472,11 @@ func (t *AnsiTerminal) HasIncomingData() (result bool) {
 }
 
 func (t *AnsiTerminal) Input(size int, mode InputMode) (result string, err error) {
+	return t.InputDefault(size, mode, "")
+}
+
+// InputDefault works like Input, but the field is prefilled with a value the user can edit.
+func (t *AnsiTerminal) InputDefault(size int, mode InputMode, value string) (result string, err error) {
 	// print field
 	var inputBuffer strings.Builder
 	var inputCounter = 0
@@ -329,8 +489,13 @@ func (t *AnsiTerminal) Input(size int, mode InputMode) (result string, err error
 	t.Printf("\x1B[%dD", size)
 	t.SetFullColor(White, Blue, false)
 
-	// We have drawn our input box, now let's get input
-	ch, err = t.WaitKey(false)
+	// We have drawn our input box. The default value is processed as if it was typed by the user.
+	pending := []rune(value)
+	if len(pending) > 0 {
+		ch, pending = pending[0], pending[1:]
+	} else {
+		ch, err = t.WaitKey(false)
+	}
 	for ch != '\r' {
 		if err != nil {
 			break
@@ -349,6 +514,14 @@ func (t *AnsiTerminal) Input(size int, mode InputMode) (result string, err error
 				t.Printf("%c %c", '\b', '\b')
 
 			}
+		case '\u0015':
+			// Ctrl-U, erase the whole line
+			for inputCounter > 0 {
+				inputCounter--
+				t.Printf("%c %c", '\b', '\b')
+			}
+			inputBuffer.Reset()
+			lastChar = 0
 
 		default:
 			if inputCounter < size {
@@ -389,15 +562,54 @@ func (t *AnsiTerminal) Input(size int, mode InputMode) (result string, err error
 			}
 		}
 		// next char
-		ch, err = t.WaitKey(false)
+		if len(pending) > 0 {
+			ch, pending = pending[0], pending[1:]
+		} else {
+			ch, err = t.WaitKey(false)
+		}
 	}
 	t.Printf("\x1B[%dC", size-inputCounter)
 	t.Print("\n")
 	t.Printf("\x1B[0m")
+	if err == ErrInterrupted {
+		// whatever was typed is discarded
+		return "", err
+	}
 	result = inputBuffer.String()
 	return
 }
 
+// InputLine reads a line of text without drawing an input field. Useful for long values that are pasted by the user.
+func (t *AnsiTerminal) InputLine(maxLength int) (result string, err error) {
+	var line []rune
+	ch, err := t.WaitKey(false)
+	for ch != '\r' {
+		if err == ErrInterrupted {
+			t.Print("\n")
+			return
+		}
+		if err != nil {
+			return
+		}
+		switch ch {
+		case '\b', '\u007F':
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				t.Printf("%c %c", '\b', '\b')
+			}
+		default:
+			if len(line) < maxLength && unicode.IsPrint(ch) {
+				line = append(line, ch)
+				t.Printf("%c", ch)
+			}
+		}
+		ch, err = t.WaitKey(false)
+	}
+	t.Print("\n")
+	result = string(line)
+	return
+}
+
 func (t *AnsiTerminal) SendTextFile(path string) {
 	privateBytes, err := ioutil.ReadFile(path)
 	if err == nil {
@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ansiterm
+
+import (
+	"strings"
+)
+
+type Capability int
+
+// Terminal capabilities. The values are identical to the bits used by the Mud Terminal Type Standard (MTTS),
+// so a bitfield received from a telnet client can be used as-is.
+// See: https://tintin.mudhalla.net/protocols/mtts/
+const (
+	CapANSI          Capability = 1
+	CapVT100         Capability = 2
+	CapUTF8          Capability = 4
+	Cap256Colors     Capability = 8
+	CapMouseTracking Capability = 16
+	CapOSCPalette    Capability = 32
+	CapScreenReader  Capability = 64
+	CapProxy         Capability = 128
+	CapTrueColor     Capability = 256
+	CapMNES          Capability = 512
+	CapMSLP          Capability = 1024
+	CapSSL           Capability = 2048
+)
+
+// SetTerminalType stores the terminal type(s) reported by the client, most specific name first.
+// If the client did not report any capabilities, we make an educated guess based on the names.
+func (t *AnsiTerminal) SetTerminalType(names []string, caps Capability) {
+	if caps == 0 {
+		for _, name := range names {
+			caps |= guessCapabilities(name)
+		}
+	}
+	t.settingsLock.Lock()
+	defer t.settingsLock.Unlock()
+	t.termTypes = append([]string(nil), names...)
+	t.capabilities = caps
+}
+
+// GetTerminalType returns the first terminal type reported by the client, or an empty string if unknown.
+func (t *AnsiTerminal) GetTerminalType() string {
+	t.settingsLock.RLock()
+	defer t.settingsLock.RUnlock()
+	if len(t.termTypes) == 0 {
+		return ""
+	}
+	return t.termTypes[0]
+}
+
+// GetTerminalTypes returns all terminal types reported by the client.
+func (t *AnsiTerminal) GetTerminalTypes() []string {
+	t.settingsLock.RLock()
+	defer t.settingsLock.RUnlock()
+	return append([]string(nil), t.termTypes...)
+}
+
+func (t *AnsiTerminal) GetCapabilities() Capability {
+	t.settingsLock.RLock()
+	defer t.settingsLock.RUnlock()
+	return t.capabilities
+}
+
+func (t *AnsiTerminal) HasCapability(c Capability) bool {
+	return t.GetCapabilities()&c == c
+}
+
+// Most clients don't support MTTS, so we look at the name to figure out what they can do.
+// BBS-style clients are assumed to use CP437, everything else is assumed to be a modern UTF-8 terminal.
+func guessCapabilities(name string) (caps Capability) {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasPrefix(name, "ansi"), strings.HasPrefix(name, "syncterm"), strings.HasPrefix(name, "netrunner"),
+		strings.HasPrefix(name, "pcansi"), strings.HasPrefix(name, "cp437"):
+		caps = CapANSI
+	case strings.HasPrefix(name, "xterm"), strings.HasPrefix(name, "linux"), strings.HasPrefix(name, "putty"),
+		strings.HasPrefix(name, "screen"), strings.HasPrefix(name, "tmux"), strings.HasPrefix(name, "rxvt"),
+		strings.HasPrefix(name, "mudlet"):
+		caps = CapANSI | CapVT100 | CapUTF8
+	case strings.HasPrefix(name, "vt"):
+		caps = CapANSI | CapVT100
+	case strings.HasPrefix(name, "dumb"), name == "":
+		caps = 0
+	default:
+		caps = CapANSI
+	}
+	if strings.Contains(name, "256color") {
+		caps |= Cap256Colors
+	}
+	if strings.Contains(name, "truecolor") || strings.Contains(name, "direct") {
+		caps |= Cap256Colors | CapTrueColor
+	}
+	return
+}
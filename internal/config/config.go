@@ -19,7 +19,9 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"net"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
@@ -31,6 +33,9 @@ const (
 	TCPTelnet ConnectionType = iota
 	TCPRaw
 	TCPSSH
+	TCPWebSocket
+	TCPTelnets
+	TCPRLogin
 )
 
 func (t ConnectionType) String() (result string) {
@@ -43,6 +48,15 @@ func (t ConnectionType) String() (result string) {
 
 	case TCPSSH:
 		result = "ssh"
+
+	case TCPWebSocket:
+		result = "websocket"
+
+	case TCPTelnets:
+		result = "telnets"
+
+	case TCPRLogin:
+		result = "rlogin"
 	default:
 		result = "unknown"
 	}
@@ -52,15 +66,23 @@ func (t ConnectionType) String() (result string) {
 // structure used for the yaml parsing
 type Config struct {
 	Database struct {
+		Type     string
+		Path     string
 		Host     string
 		Name     string
 		User     string
 		Password string
 		Port     int
+		SSLMode  string `yaml:"sslMode"`
 	}
 	Options struct {
-		LogLevel      string `yaml:"logLevel"`
-		SSHPrivateKey string `yaml:"sshPrivateKey"`
+		LogLevel         string `yaml:"logLevel"`
+		SSHPrivateKey    string `yaml:"sshPrivateKey"`
+		SSHHostKeyDir    string `yaml:"sshHostKeyDir"`
+		ShutdownDrain    *uint  `yaml:"shutdownDrain"` // seconds
+		MaxNodes         int    `yaml:"maxNodes"`
+		SSHAnonymousUser string `yaml:"sshAnonymousUser"`
+		BanList          string `yaml:"banList"`
 	}
 
 	Listeners []struct {
@@ -68,6 +90,32 @@ type Config struct {
 		Port        uint16
 		Protocol    string
 		ConvertUTF8 bool `yaml:"convertUTF8"`
+		StartTLS    bool `yaml:"startTLS"`
+		// connections start with a PROXY protocol header from a load balancer
+		ProxyProtocol bool `yaml:"proxyProtocol"`
+		// rlogin peers that can login without password (IP addresses or CIDR ranges)
+		TrustedPeers []string `yaml:"trustedPeers"`
+		TLS          struct {
+			Certificate string
+			Key         string
+		}
+		Keepalive struct {
+			Enabled  bool
+			Interval uint // seconds
+			Timeout  uint // seconds
+			Method   string
+		}
+		SSH struct {
+			HandshakeTimeout uint `yaml:"handshakeTimeout"` // seconds
+			MaxChannels      int  `yaml:"maxChannels"`
+		}
+		// zero means the default, negative means no limit
+		Limits struct {
+			Rate           float64 // per minute
+			Burst          int
+			MaxPerIP       int `yaml:"maxPerIP"`
+			MaxConnections int `yaml:"maxConnections"`
+		}
 	}
 	Prometheus struct {
 		Enabled bool
@@ -75,13 +123,45 @@ type Config struct {
 		Port    uint16
 		Path    string
 	}
+	Mail struct {
+		Type     string
+		Host     string
+		Port     uint16
+		Username string
+		Password string
+		From     string
+		Dir      string
+	}
+	Passwords struct {
+		Algorithm  string
+		BcryptCost int `yaml:"bcryptCost"`
+		Argon2     struct {
+			Memory      uint32 // KiB
+			Iterations  uint32
+			Parallelism uint8
+		}
+	}
 }
 
 // final structure for program options
 type ProgramOptions struct {
-	LogLevel      log.Level
+	LogLevel log.Level
+	// optional host key, overrides the generated key of the same type
 	SSHPrivateKey string
-	Prometheus    PrometheusConfig
+	// directory with the generated ssh host keys
+	SSHHostKeyDir string
+	// ssh username that can login without authentication, to create a new account in the game
+	SSHAnonymousUser string
+	// file with banned IP addresses and ranges
+	BanList string
+	// time players get to finish what they're doing when the server shuts down
+	ShutdownDrain time.Duration
+	// maximum number of sessions, zero means unlimited
+	MaxNodes   int
+	Database   DatabaseConfig
+	Mail       MailConfig
+	Passwords  PasswordConfig
+	Prometheus PrometheusConfig
 }
 
 // final structure for listener config
@@ -90,15 +170,107 @@ type Listener struct {
 	Port        uint16
 	ListenType  ConnectionType
 	ConvertUTF8 bool
+	Keepalive   KeepaliveConfig
+	SSH         SSHConfig
+	TLS         TLSConfig
+	// offer START_TLS on plain telnet listeners
+	StartTLS bool
+	// connections start with a PROXY protocol header, which contains the real address of the client
+	ProxyProtocol bool
+	// rlogin peers whose users are logged in automatically
+	TrustedPeers []*net.IPNet
+	Limits       LimitsConfig
+}
+
+// final structure for the certificate of telnets listeners
+type TLSConfig struct {
+	Certificate string
+	Key         string
+}
+
+// final structure for telnet keepalive config
+type KeepaliveConfig struct {
+	Enabled  bool
+	Interval time.Duration
+	Timeout  time.Duration
+	// send NOP instead of DO TIMING-MARK
+	UseNOP bool
+}
+
+// final structure for ssh connection limits
+type SSHConfig struct {
+	// maximum time a client can take to finish the handshake and authentication
+	HandshakeTimeout time.Duration
+	// maximum number of open channels per connection. Only one of them can be an interactive session.
+	MaxChannels int
+}
+
+// final structure for the connection limits of a listener. Zero means unlimited.
+type LimitsConfig struct {
+	// new connections per minute from the same address, and the number of connections that can be opened at once
+	// before the rate applies
+	Rate  float64
+	Burst int
+	// open connections from the same address
+	MaxPerIP int
+	// open connections on the listener
+	MaxConnections int
 }
 
+// where the user accounts are stored
+const (
+	DatabasePostgres = "postgres"
+	DatabaseFile     = "file"
+)
+
 // final structure for db config
 type DatabaseConfig struct {
+	Type string
+	// file database
+	Path string
+	// postgres database
 	Host     string
 	Port     uint16
 	Database string
 	User     string
 	Password string
+	SSLMode  string
+}
+
+// how we send email. Without a mailer, email addresses aren't verified.
+const (
+	MailNone = ""
+	MailSMTP = "smtp"
+	MailFile = "file"
+)
+
+// final structure for mail config
+type MailConfig struct {
+	Type string
+	From string
+	// smtp server
+	Host     string
+	Port     uint16
+	Username string
+	Password string
+	// directory where the file mailer writes the messages
+	Dir string
+}
+
+// password hashing algorithms
+const (
+	PasswordArgon2id = "argon2id"
+	PasswordBcrypt   = "bcrypt"
+)
+
+// final structure for password hashing. Passwords hashed with other parameters are rehashed when the user logs in.
+type PasswordConfig struct {
+	Algorithm  string
+	BcryptCost int
+	// argon2id memory in KiB
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
 }
 
 // final structure for prometheus endpoint
@@ -160,44 +332,328 @@ func ParseConfig(configFile string) ([]Listener, error) {
 		AppOptions.Prometheus.Path = config.Prometheus.Path
 	}
 
-	// set private key for ssh listeners
+	if config.Options.MaxNodes < 0 {
+		return nil, fmt.Errorf("Invalid value for maxNodes: %d", config.Options.MaxNodes)
+	}
+	AppOptions.MaxNodes = config.Options.MaxNodes
+
+	// set shutdown drain period, zero disconnects everybody immediately
+	if config.Options.ShutdownDrain == nil {
+		AppOptions.ShutdownDrain = 30 * time.Second
+	} else {
+		AppOptions.ShutdownDrain = time.Duration(*config.Options.ShutdownDrain) * time.Second
+	}
+
+	// set private keys for ssh listeners
 	AppOptions.SSHPrivateKey = config.Options.SSHPrivateKey
+	if config.Options.SSHHostKeyDir == "" {
+		AppOptions.SSHHostKeyDir = "security"
+	} else {
+		AppOptions.SSHHostKeyDir = config.Options.SSHHostKeyDir
+	}
+	if config.Options.SSHAnonymousUser == "" {
+		AppOptions.SSHAnonymousUser = "new"
+	} else {
+		AppOptions.SSHAnonymousUser = config.Options.SSHAnonymousUser
+	}
+	if config.Options.BanList == "" {
+		AppOptions.BanList = "security/bans.txt"
+	} else {
+		AppOptions.BanList = config.Options.BanList
+	}
+	// user database. Without a type, we use postgres if a host is configured.
+	dbType := strings.ToLower(config.Database.Type)
+	if dbType == "" {
+		if config.Database.Host != "" {
+			dbType = DatabasePostgres
+		} else {
+			dbType = DatabaseFile
+		}
+	}
+	switch dbType {
+	case DatabasePostgres:
+		if config.Database.Host == "" || config.Database.Name == "" {
+			return nil, fmt.Errorf("The postgres database needs a host and a name")
+		}
+		if config.Database.Port < 0 || config.Database.Port > 65535 {
+			return nil, fmt.Errorf("Invalid database port: %d", config.Database.Port)
+		}
+		AppOptions.Database = DatabaseConfig{
+			Type:     DatabasePostgres,
+			Host:     config.Database.Host,
+			Port:     uint16(config.Database.Port),
+			Database: config.Database.Name,
+			User:     config.Database.User,
+			Password: config.Database.Password,
+			SSLMode:  config.Database.SSLMode,
+		}
+		if AppOptions.Database.Port == 0 {
+			AppOptions.Database.Port = 5432
+		}
+	case DatabaseFile:
+		AppOptions.Database = DatabaseConfig{
+			Type: DatabaseFile,
+			Path: config.Database.Path,
+		}
+		if AppOptions.Database.Path == "" {
+			AppOptions.Database.Path = "data/users.json"
+		}
+	default:
+		return nil, fmt.Errorf("Invalid database type: %s. Valid values are: postgres, file", config.Database.Type)
+	}
+
+	// mailer for verification codes
+	switch strings.ToLower(config.Mail.Type) {
+	case MailNone:
+		AppOptions.Mail = MailConfig{}
+	case MailSMTP:
+		if config.Mail.Host == "" || config.Mail.From == "" {
+			return nil, fmt.Errorf("The smtp mailer needs a host and a from address")
+		}
+		AppOptions.Mail = MailConfig{
+			Type:     MailSMTP,
+			From:     config.Mail.From,
+			Host:     config.Mail.Host,
+			Port:     config.Mail.Port,
+			Username: config.Mail.Username,
+			Password: config.Mail.Password,
+		}
+		if AppOptions.Mail.Port == 0 {
+			AppOptions.Mail.Port = 25
+		}
+	case MailFile:
+		AppOptions.Mail = MailConfig{
+			Type: MailFile,
+			From: config.Mail.From,
+			Dir:  config.Mail.Dir,
+		}
+		if AppOptions.Mail.From == "" {
+			AppOptions.Mail.From = "tobw@localhost"
+		}
+		if AppOptions.Mail.Dir == "" {
+			AppOptions.Mail.Dir = "mail"
+		}
+	default:
+		return nil, fmt.Errorf("Invalid mail type: %s. Valid values are: smtp, file", config.Mail.Type)
+	}
+
+	// password hashing, the defaults follow RFC 9106 and OWASP
+	AppOptions.Passwords = PasswordConfig{
+		Algorithm:         strings.ToLower(config.Passwords.Algorithm),
+		BcryptCost:        config.Passwords.BcryptCost,
+		Argon2Memory:      config.Passwords.Argon2.Memory,
+		Argon2Iterations:  config.Passwords.Argon2.Iterations,
+		Argon2Parallelism: config.Passwords.Argon2.Parallelism,
+	}
+	switch AppOptions.Passwords.Algorithm {
+	case "":
+		AppOptions.Passwords.Algorithm = PasswordArgon2id
+	case PasswordArgon2id, PasswordBcrypt:
+	default:
+		return nil, fmt.Errorf("Invalid password algorithm: %s. Valid values are: argon2id, bcrypt", config.Passwords.Algorithm)
+	}
+	if AppOptions.Passwords.BcryptCost == 0 {
+		AppOptions.Passwords.BcryptCost = 12
+	}
+	if AppOptions.Passwords.BcryptCost < 10 || AppOptions.Passwords.BcryptCost > 31 {
+		return nil, fmt.Errorf("Invalid bcrypt cost: %d. Valid values are 10 to 31", AppOptions.Passwords.BcryptCost)
+	}
+	if AppOptions.Passwords.Argon2Memory == 0 {
+		AppOptions.Passwords.Argon2Memory = 64 * 1024
+	}
+	if AppOptions.Passwords.Argon2Iterations == 0 {
+		AppOptions.Passwords.Argon2Iterations = 3
+	}
+	if AppOptions.Passwords.Argon2Parallelism == 0 {
+		AppOptions.Passwords.Argon2Parallelism = 4
+	}
+	if AppOptions.Passwords.Argon2Memory < 8*uint32(AppOptions.Passwords.Argon2Parallelism) {
+		return nil, fmt.Errorf("Argon2 memory must be at least 8 KiB per thread")
+	}
+
 	// validate listener configuration
 	if len(config.Listeners) == 0 {
 		return nil, fmt.Errorf("No listeners are defined in the configuration file")
 	}
 	listeners := []Listener{}
 	for _, cfgListener := range config.Listeners {
+		// set default keepalive values
+		keepalive := KeepaliveConfig{
+			Enabled:  cfgListener.Keepalive.Enabled,
+			Interval: time.Duration(cfgListener.Keepalive.Interval) * time.Second,
+			Timeout:  time.Duration(cfgListener.Keepalive.Timeout) * time.Second,
+		}
+		if keepalive.Interval == 0 {
+			keepalive.Interval = 60 * time.Second
+		}
+		if keepalive.Timeout == 0 {
+			keepalive.Timeout = 30 * time.Second
+		}
+		switch strings.ToLower(cfgListener.Keepalive.Method) {
+		case "", "timingmark":
+			keepalive.UseNOP = false
+		case "nop":
+			keepalive.UseNOP = true
+		default:
+			return nil, fmt.Errorf("Invalid value for keepalive method. Valid values are: timingmark, nop. Received value: %s", cfgListener.Keepalive.Method)
+		}
+
+		// set default connection limits
+		limits := LimitsConfig{
+			Rate:           defaultLimit(cfgListener.Limits.Rate, 20),
+			Burst:          int(defaultLimit(float64(cfgListener.Limits.Burst), 10)),
+			MaxPerIP:       int(defaultLimit(float64(cfgListener.Limits.MaxPerIP), 5)),
+			MaxConnections: int(defaultLimit(float64(cfgListener.Limits.MaxConnections), 200)),
+		}
+		if limits.Rate > 0 && limits.Burst == 0 {
+			limits.Burst = 1
+		}
+
+		tlsConfig := TLSConfig{
+			Certificate: cfgListener.TLS.Certificate,
+			Key:         cfgListener.TLS.Key,
+		}
+		hasCertificate := tlsConfig.Certificate != "" && tlsConfig.Key != ""
+
 		switch strings.ToLower(cfgListener.Protocol) {
 		case "telnet":
+			if cfgListener.StartTLS && !hasCertificate {
+				return nil, fmt.Errorf("startTLS requires a certificate and key on telnet listener %s:%d", cfgListener.Address, cfgListener.Port)
+			}
 			l := Listener{
-				Address:     cfgListener.Address,
-				Port:        cfgListener.Port,
-				ListenType:  TCPTelnet,
-				ConvertUTF8: cfgListener.ConvertUTF8,
+				Address:       cfgListener.Address,
+				Port:          cfgListener.Port,
+				ListenType:    TCPTelnet,
+				ConvertUTF8:   cfgListener.ConvertUTF8,
+				ProxyProtocol: cfgListener.ProxyProtocol,
+				Limits:        limits,
+				Keepalive:     keepalive,
+				TLS:           tlsConfig,
+				StartTLS:      cfgListener.StartTLS,
+			}
+			listeners = append(listeners, l)
+		case "telnets":
+			if !hasCertificate {
+				return nil, fmt.Errorf("A certificate and key are required on telnets listener %s:%d", cfgListener.Address, cfgListener.Port)
+			}
+			l := Listener{
+				Address:       cfgListener.Address,
+				Port:          cfgListener.Port,
+				ListenType:    TCPTelnets,
+				ConvertUTF8:   cfgListener.ConvertUTF8,
+				ProxyProtocol: cfgListener.ProxyProtocol,
+				Limits:        limits,
+				Keepalive:     keepalive,
+				TLS:           tlsConfig,
 			}
 			listeners = append(listeners, l)
 		case "ssh":
+			// set default ssh limits
+			sshConfig := SSHConfig{
+				HandshakeTimeout: time.Duration(cfgListener.SSH.HandshakeTimeout) * time.Second,
+				MaxChannels:      cfgListener.SSH.MaxChannels,
+			}
+			if sshConfig.HandshakeTimeout == 0 {
+				sshConfig.HandshakeTimeout = 30 * time.Second
+			}
+			if sshConfig.MaxChannels <= 0 {
+				sshConfig.MaxChannels = 4
+			}
 			l := Listener{
-				Address:     cfgListener.Address,
-				Port:        cfgListener.Port,
-				ListenType:  TCPSSH,
-				ConvertUTF8: cfgListener.ConvertUTF8,
+				Address:       cfgListener.Address,
+				Port:          cfgListener.Port,
+				ListenType:    TCPSSH,
+				ConvertUTF8:   cfgListener.ConvertUTF8,
+				ProxyProtocol: cfgListener.ProxyProtocol,
+				Limits:        limits,
+				Keepalive:     keepalive,
+				SSH:           sshConfig,
+			}
+			listeners = append(listeners, l)
+
+		case "websocket":
+			// browsers always use UTF-8
+			l := Listener{
+				Address:       cfgListener.Address,
+				Port:          cfgListener.Port,
+				ListenType:    TCPWebSocket,
+				ConvertUTF8:   true,
+				ProxyProtocol: cfgListener.ProxyProtocol,
+				Limits:        limits,
+			}
+			listeners = append(listeners, l)
+
+		case "rlogin":
+			trustedPeers, err := parseNetworks(cfgListener.TrustedPeers)
+			if err != nil {
+				return nil, err
+			}
+			l := Listener{
+				Address:       cfgListener.Address,
+				Port:          cfgListener.Port,
+				ListenType:    TCPRLogin,
+				ConvertUTF8:   cfgListener.ConvertUTF8,
+				ProxyProtocol: cfgListener.ProxyProtocol,
+				Limits:        limits,
+				TrustedPeers:  trustedPeers,
 			}
 			listeners = append(listeners, l)
 
 		case "raw":
 			l := Listener{
-				Address:     cfgListener.Address,
-				Port:        cfgListener.Port,
-				ListenType:  TCPRaw,
-				ConvertUTF8: cfgListener.ConvertUTF8,
+				Address:       cfgListener.Address,
+				Port:          cfgListener.Port,
+				ListenType:    TCPRaw,
+				ConvertUTF8:   cfgListener.ConvertUTF8,
+				ProxyProtocol: cfgListener.ProxyProtocol,
+				Limits:        limits,
 			}
 			listeners = append(listeners, l)
 
 		default:
-			return nil, fmt.Errorf("Invalid value for protocol. Valid values are: ssh, telnet, raw. Received value: %s", cfgListener.Protocol)
+			return nil, fmt.Errorf("Invalid value for protocol. Valid values are: ssh, telnet, telnets, raw, rlogin, websocket. Received value: %s", cfgListener.Protocol)
 		}
 	}
 	return listeners, nil
 }
+
+// parses a list of IP addresses and CIDR ranges. A single address is a range with only that address.
+func parseNetworks(values []string) ([]*net.IPNet, error) {
+	networks := []*net.IPNet{}
+	for _, value := range values {
+		network, err := ParseNetwork(value)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// returns the default for zero, and zero (unlimited) for negative values
+func defaultLimit(value float64, defaultValue float64) float64 {
+	if value == 0 {
+		return defaultValue
+	}
+	if value < 0 {
+		return 0
+	}
+	return value
+}
+
+// ParseNetwork parses an IP address or CIDR range. A single address is a range with only that address.
+func ParseNetwork(value string) (*net.IPNet, error) {
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("Invalid IP address: %s", value)
+		}
+		if ip.To4() != nil {
+			value += "/32"
+		} else {
+			value += "/128"
+		}
+	}
+	_, network, err := net.ParseCIDR(value)
+	return network, err
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package guard
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeroenjacobs79/tobw/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// The ban list is a text file with an IP address or CIDR range per line, optionally followed by a comment:
+//
+//	203.0.113.7        # spamming the chat rooms
+//	198.51.100.0/24
+//
+// Changes to the file are picked up while the server is running.
+
+// how often we check if the file was changed
+const banReloadInterval = 5 * time.Second
+
+type ban struct {
+	network *net.IPNet
+	comment string
+}
+
+var (
+	bans       []ban
+	banPath    string
+	banModTime time.Time
+	banChecked time.Time
+	banLock    sync.Mutex
+)
+
+// LoadBans reads the ban list. The file doesn't need to exist.
+func LoadBans(path string) error {
+	banLock.Lock()
+	defer banLock.Unlock()
+	banPath = path
+	return reloadBans()
+}
+
+// reads the file if it was changed. Call with the lock held.
+func reloadBans() error {
+	banChecked = time.Now()
+	info, err := os.Stat(banPath)
+	if os.IsNotExist(err) {
+		bans = nil
+		banModTime = time.Time{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(banModTime) {
+		return nil
+	}
+	file, err := os.Open(banPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var loaded []ban
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		var comment string
+		if i := strings.Index(line, "#"); i >= 0 {
+			comment = strings.TrimSpace(line[i+1:])
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		network, err := config.ParseNetwork(line)
+		if err != nil {
+			return fmt.Errorf("%s line %d: %s", banPath, lineNumber, err.Error())
+		}
+		loaded = append(loaded, ban{network: network, comment: comment})
+	}
+	err = scanner.Err()
+	if err != nil {
+		return err
+	}
+	bans = loaded
+	banModTime = info.ModTime()
+	log.Debugf("Loaded %d bans from %s", len(bans), banPath)
+	return nil
+}
+
+// writes the ban list to a temporary file, and replaces the old file with it. Call with the lock held.
+func saveBans() error {
+	var content strings.Builder
+	for _, b := range bans {
+		content.WriteString(b.network.String())
+		if b.comment != "" {
+			content.WriteString(" # " + b.comment)
+		}
+		content.WriteString("\n")
+	}
+	dir := filepath.Dir(banPath)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+	tmpFile, err := ioutil.TempFile(dir, ".bans-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	_, err = tmpFile.WriteString(content.String())
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmpFile.Name(), banPath)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(banPath)
+	if err == nil {
+		banModTime = info.ModTime()
+	}
+	return err
+}
+
+// IsBanned returns true if the address is on the ban list. The origin is an address with or without port.
+func IsBanned(origin string) bool {
+	host, _, err := net.SplitHostPort(origin)
+	if err != nil {
+		host = origin
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	banLock.Lock()
+	defer banLock.Unlock()
+	if banPath != "" && time.Since(banChecked) > banReloadInterval {
+		err := reloadBans()
+		if err != nil {
+			// keep the bans we have
+			log.Errorln(err.Error())
+		}
+	}
+	for _, b := range bans {
+		if b.network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ban adds an address or range to the ban list.
+func Ban(network string, comment string) error {
+	parsed, err := config.ParseNetwork(network)
+	if err != nil {
+		return err
+	}
+	banLock.Lock()
+	defer banLock.Unlock()
+	if banPath == "" {
+		return fmt.Errorf("No ban list is loaded")
+	}
+	err = reloadBans()
+	if err != nil {
+		return err
+	}
+	for _, b := range bans {
+		if b.network.String() == parsed.String() {
+			return nil
+		}
+	}
+	bans = append(bans, ban{network: parsed, comment: strings.Replace(comment, "\n", " ", -1)})
+	return saveBans()
+}
+
+// Unban removes an address or range from the ban list. It has to match an entry exactly.
+func Unban(network string) error {
+	parsed, err := config.ParseNetwork(network)
+	if err != nil {
+		return err
+	}
+	banLock.Lock()
+	defer banLock.Unlock()
+	if banPath == "" {
+		return fmt.Errorf("No ban list is loaded")
+	}
+	err = reloadBans()
+	if err != nil {
+		return err
+	}
+	for i, b := range bans {
+		if b.network.String() == parsed.String() {
+			bans = append(bans[:i:i], bans[i+1:]...)
+			return saveBans()
+		}
+	}
+	return fmt.Errorf("%s is not on the ban list", parsed)
+}
+
+// Bans returns the ban list, in the format of the file.
+func Bans() (result []string) {
+	banLock.Lock()
+	defer banLock.Unlock()
+	for _, b := range bans {
+		line := b.network.String()
+		if b.comment != "" {
+			line += " # " + b.comment
+		}
+		result = append(result, line)
+	}
+	return
+}
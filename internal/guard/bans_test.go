@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package guard
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBanList = `# the ban list
+203.0.113.7        # spamming the chat rooms
+  198.51.100.0/24
+2001:db8::/32 # a whole provider
+
+`
+
+// loads a ban list from a temporary directory. Call the returned function to remove it again.
+func loadTestBans(t *testing.T, content string) (path string, remove func()) {
+	dir, err := ioutil.TempDir("", "tobw-bans")
+	if err != nil {
+		t.Fatal(err)
+	}
+	remove = func() {
+		banLock.Lock()
+		bans, banPath, banModTime = nil, "", time.Time{}
+		banLock.Unlock()
+		os.RemoveAll(dir)
+	}
+	path = filepath.Join(dir, "bans.txt")
+	if content != "" {
+		err = ioutil.WriteFile(path, []byte(content), 0600)
+	}
+	if err == nil {
+		err = LoadBans(path)
+	}
+	if err != nil {
+		remove()
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestIsBanned(t *testing.T) {
+	_, remove := loadTestBans(t, testBanList)
+	defer remove()
+	for origin, want := range map[string]bool{
+		"203.0.113.7":           true,
+		"203.0.113.7:2323":      true,
+		"::ffff:203.0.113.7":    true,
+		"203.0.113.8":           false,
+		"198.51.100.200:22":     true,
+		"198.51.101.1":          false,
+		"[2001:db8:ffff::1]:22": true,
+		"2001:db9::1":           false,
+		"no address":            false,
+	} {
+		if got := IsBanned(origin); got != want {
+			t.Errorf("IsBanned(%q) = %t, want %t", origin, got, want)
+		}
+	}
+	want := []string{"203.0.113.7/32 # spamming the chat rooms", "198.51.100.0/24", "2001:db8::/32 # a whole provider"}
+	if got := Bans(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Bans() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadBansWithoutFile(t *testing.T) {
+	_, remove := loadTestBans(t, "")
+	defer remove()
+	if IsBanned("203.0.113.7") || len(Bans()) != 0 {
+		t.Error("banned without a ban list")
+	}
+}
+
+func TestLoadBansReportsLine(t *testing.T) {
+	path, remove := loadTestBans(t, "")
+	defer remove()
+	err := ioutil.WriteFile(path, []byte("192.0.2.1\n192.0.2.300 # typo\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = LoadBans(path)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("error %v, want one about line 2", err)
+	}
+}
+
+func TestBanAndUnban(t *testing.T) {
+	path, remove := loadTestBans(t, "203.0.113.7 # spamming\n")
+	defer remove()
+	if err := Ban("198.51.100.0/24", "too many\nfailed logins"); err != nil {
+		t.Fatal(err)
+	}
+	// banning it again changes nothing
+	if err := Ban("198.51.100.0/24", "again"); err != nil {
+		t.Fatal(err)
+	}
+	if !IsBanned("198.51.100.1") {
+		t.Error("new ban not active")
+	}
+	content, _ := ioutil.ReadFile(path)
+	if want := "203.0.113.7/32 # spamming\n198.51.100.0/24 # too many failed logins\n"; string(content) != want {
+		t.Errorf("ban list %q, want %q", content, want)
+	}
+
+	if err := Unban("203.0.113.7"); err != nil {
+		t.Fatal(err)
+	}
+	if IsBanned("203.0.113.7") {
+		t.Error("still banned after unban")
+	}
+	if Unban("203.0.113.7") == nil {
+		t.Error("unbanning an address that isn't banned succeeded")
+	}
+	content, _ = ioutil.ReadFile(path)
+	if want := "198.51.100.0/24 # too many failed logins\n"; string(content) != want {
+		t.Errorf("ban list %q, want %q", content, want)
+	}
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package guard
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeroenjacobs79/tobw/internal/monitoring"
+	log "github.com/sirupsen/logrus"
+)
+
+// Protection against password guessing. Failed logins are counted per account and per origin. After a few failures,
+// the next attempt has to wait, and the wait doubles with every failure. Accounts with too many failures are locked
+// for a while, and origins that keep trying are banned.
+
+const (
+	// failures before we start to slow down
+	freeAttempts = 3
+	minBackoff   = 2 * time.Second
+	maxBackoff   = 5 * time.Minute
+	// account lockout
+	lockoutThreshold = 10
+	lockoutDuration  = 15 * time.Minute
+	// failures from the same origin before it's added to the ban list
+	banThreshold = 100
+	// counters are reset when there haven't been failures for this long
+	forgetAfter = 1 * time.Hour
+	// how often we remove the counters that were reset
+	pruneInterval = 5 * time.Minute
+)
+
+type failures struct {
+	count        int
+	last         time.Time
+	blockedUntil time.Time
+}
+
+var (
+	accountFailures = make(map[string]*failures)
+	originFailures  = make(map[string]*failures)
+	failureLock     sync.Mutex
+	lastPrune       time.Time
+)
+
+func accountKey(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// OriginKey returns the address we count failures for. IPv6 clients usually get a whole /64, so we count them per
+// /64 instead of per address. The origin is an address with or without port.
+func OriginKey(origin string) string {
+	host, _, err := net.SplitHostPort(origin)
+	if err != nil {
+		host = origin
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return host
+	}
+	if ip.To4() != nil {
+		return ip.String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(64, 128)), Mask: net.CIDRMask(64, 128)}).String()
+}
+
+// returns the record, or nil if there is none or it's expired. Call with the lock held.
+func lookup(records map[string]*failures, key string, now time.Time) *failures {
+	record := records[key]
+	if record != nil && now.Sub(record.last) > forgetAfter && now.After(record.blockedUntil) {
+		delete(records, key)
+		return nil
+	}
+	return record
+}
+
+// removes the expired records, so addresses that tried once don't stay in memory forever. Call with the lock held.
+func prune(records map[string]*failures, now time.Time) {
+	for key := range records {
+		lookup(records, key, now)
+	}
+}
+
+// CheckLogin returns how long the player has to wait before they can try to login to the account from this origin.
+// Zero means they can try now.
+func CheckLogin(username string, origin string) time.Duration {
+	failureLock.Lock()
+	defer failureLock.Unlock()
+	now := time.Now()
+	var wait time.Duration
+	for _, record := range []*failures{
+		lookup(accountFailures, accountKey(username), now),
+		lookup(originFailures, OriginKey(origin), now),
+	} {
+		if record != nil && record.blockedUntil.Sub(now) > wait {
+			wait = record.blockedUntil.Sub(now)
+		}
+	}
+	if wait > 0 {
+		monitoring.ThrottledLogins.Inc()
+		log.Infof("%s - Login for user %s throttled for %s", origin, username, wait.Round(time.Second))
+	}
+	return wait
+}
+
+// RecordFailure counts a wrong password or code.
+func RecordFailure(username string, origin string) {
+	monitoring.FailedLogins.Inc()
+	failureLock.Lock()
+	now := time.Now()
+	if now.Sub(lastPrune) >= pruneInterval {
+		prune(accountFailures, now)
+		prune(originFailures, now)
+		lastPrune = now
+	}
+	account := recordFailure(accountFailures, accountKey(username), now)
+	originKey := OriginKey(origin)
+	originRecord := recordFailure(originFailures, originKey, now)
+	if account.count >= lockoutThreshold {
+		account.blockedUntil = now.Add(lockoutDuration)
+	}
+	failureLock.Unlock()
+
+	if account.count == lockoutThreshold {
+		monitoring.AccountLockouts.Inc()
+		log.Warnf("%s - Account %s locked for %s after %d failed logins", origin, username, lockoutDuration, account.count)
+	}
+	if originRecord.count == banThreshold {
+		log.Warnf("%s - Banning %s after %d failed logins", origin, originKey, originRecord.count)
+		err := Ban(originKey, "too many failed logins")
+		if err != nil {
+			log.Errorln(err.Error())
+		}
+	}
+}
+
+// call with the lock held
+func recordFailure(records map[string]*failures, key string, now time.Time) *failures {
+	record := lookup(records, key, now)
+	if record == nil {
+		record = &failures{}
+		records[key] = record
+	}
+	record.count++
+	record.last = now
+	if record.count >= freeAttempts {
+		backoff := maxBackoff
+		if shift := uint(record.count - freeAttempts); shift < 16 {
+			backoff = minBackoff << shift
+		}
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+		record.blockedUntil = now.Add(backoff)
+	}
+	return record
+}
+
+// RecordSuccess resets the counter of the account. The counter of the origin is kept, otherwise someone who guesses
+// passwords could reset it by logging in to their own account now and then. It's forgotten when the failures stop.
+func RecordSuccess(username string) {
+	failureLock.Lock()
+	defer failureLock.Unlock()
+	delete(accountFailures, accountKey(username))
+}
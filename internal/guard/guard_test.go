@@ -0,0 +1,152 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package guard
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// the counters are global, every test starts without failures
+func forgetFailures() {
+	failureLock.Lock()
+	defer failureLock.Unlock()
+	accountFailures = make(map[string]*failures)
+	originFailures = make(map[string]*failures)
+}
+
+func TestOriginKey(t *testing.T) {
+	for origin, want := range map[string]string{
+		"192.0.2.1:2323":               "192.0.2.1",
+		"192.0.2.1":                    "192.0.2.1",
+		"[::ffff:192.0.2.1]:23":        "192.0.2.1",
+		"[2001:db8:1:2:3:4:5:6]:22":    "2001:db8:1:2::/64",
+		"2001:db8:1:2:ffff::1":         "2001:db8:1:2::/64",
+		"websocket client, no address": "websocket client, no address",
+	} {
+		if got := OriginKey(origin); got != want {
+			t.Errorf("OriginKey(%q) = %q, want %q", origin, got, want)
+		}
+	}
+}
+
+// the wait doubles with every failure after the free attempts, up to the maximum
+func TestBackoff(t *testing.T) {
+	now := time.Now()
+	records := make(map[string]*failures)
+	want := time.Duration(0)
+	for count := 1; count <= freeAttempts+20; count++ {
+		record := recordFailure(records, "arthur", now)
+		switch {
+		case count == freeAttempts:
+			want = minBackoff
+		case count > freeAttempts:
+			want *= 2
+			if want > maxBackoff {
+				want = maxBackoff
+			}
+		}
+		var wait time.Duration
+		if !record.blockedUntil.IsZero() {
+			wait = record.blockedUntil.Sub(now)
+		}
+		if wait != want {
+			t.Fatalf("after %d failures: wait %s, want %s", count, wait, want)
+		}
+	}
+}
+
+func TestFailuresAreForgotten(t *testing.T) {
+	now := time.Now()
+	records := map[string]*failures{
+		"recent":       {count: 5, last: now.Add(-time.Minute)},
+		"old":          {count: 5, last: now.Add(-forgetAfter - time.Minute)},
+		"old, blocked": {count: 5, last: now.Add(-forgetAfter - time.Minute), blockedUntil: now.Add(time.Minute)},
+	}
+	if lookup(records, "recent", now) == nil {
+		t.Error("recent failures forgotten")
+	}
+	if lookup(records, "old", now) != nil || records["old"] != nil {
+		t.Error("old failures not forgotten")
+	}
+	if lookup(records, "old, blocked", now) == nil {
+		t.Error("failures forgotten while still blocked")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Now()
+	records := map[string]*failures{
+		"recent":       {count: 1, last: now.Add(-time.Minute)},
+		"old":          {count: 1, last: now.Add(-forgetAfter - time.Minute)},
+		"old, blocked": {count: 20, last: now.Add(-forgetAfter - time.Minute), blockedUntil: now.Add(time.Minute)},
+	}
+	prune(records, now)
+	if len(records) != 2 || records["old"] != nil {
+		t.Errorf("got %d records after pruning, want only the recent and the blocked one", len(records))
+	}
+}
+
+func TestThrottling(t *testing.T) {
+	forgetFailures()
+	defer forgetFailures()
+	for i := 1; i < freeAttempts; i++ {
+		RecordFailure("Arthur", "192.0.2.1:1000")
+	}
+	if wait := CheckLogin("arthur", "192.0.2.1:1001"); wait != 0 {
+		t.Fatalf("throttled for %s during the free attempts", wait)
+	}
+	RecordFailure("Arthur", "192.0.2.1:1000")
+
+	// the account is throttled from everywhere, and the origin for every account
+	if CheckLogin(" ARTHUR ", "198.51.100.1:1000") == 0 {
+		t.Error("account not throttled from another origin")
+	}
+	if CheckLogin("lancelot", "192.0.2.1:1001") == 0 {
+		t.Error("origin not throttled for another account")
+	}
+	if wait := CheckLogin("lancelot", "198.51.100.1:1000"); wait != 0 {
+		t.Errorf("unrelated login throttled for %s", wait)
+	}
+	if wait := CheckLogin("arthur", "192.0.2.1"); wait > minBackoff {
+		t.Errorf("throttled for %s, want at most %s", wait, minBackoff)
+	}
+
+	// a successful login doesn't help the origin, only the account
+	RecordSuccess("arthur")
+	if wait := CheckLogin("arthur", "198.51.100.1:1000"); wait != 0 {
+		t.Errorf("account throttled for %s after a successful login", wait)
+	}
+	if CheckLogin("lancelot", "192.0.2.1:1001") == 0 {
+		t.Error("origin not throttled anymore after a successful login")
+	}
+}
+
+func TestLockout(t *testing.T) {
+	forgetFailures()
+	defer forgetFailures()
+	// every guess from another address, so only the account is locked
+	for i := 1; i <= lockoutThreshold; i++ {
+		RecordFailure("arthur", fmt.Sprintf("192.0.2.%d", i))
+	}
+	if wait := CheckLogin("arthur", "198.51.100.1"); wait <= lockoutDuration-time.Minute || wait > lockoutDuration {
+		t.Errorf("locked account: wait %s, want %s", wait, lockoutDuration)
+	}
+	if wait := CheckLogin("lancelot", "192.0.2.1"); wait != 0 {
+		t.Errorf("other account throttled for %s", wait)
+	}
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package hostkey
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/ssh"
+)
+
+// Host keys of the ssh server. We use the same file names as OpenSSH, so existing keys can be copied over.
+// The preferred algorithms come first.
+
+const (
+	rsaKeyBits = 3072
+	// comment stored in the generated keys
+	keyComment = "tobw"
+)
+
+type keyType struct {
+	name     string
+	filename string
+	generate func() (*pem.Block, error)
+}
+
+var keyTypes = []keyType{
+	{name: "ed25519", filename: "ssh_host_ed25519_key", generate: generateED25519},
+	{name: "ecdsa", filename: "ssh_host_ecdsa_key", generate: generateECDSA},
+	{name: "rsa", filename: "ssh_host_rsa_key", generate: generateRSA},
+}
+
+// Generate creates the host keys that don't exist yet in dir. Existing keys are never overwritten.
+// Returns the paths of the keys that were created.
+func Generate(dir string) (created []string, err error) {
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return
+	}
+	for _, kt := range keyTypes {
+		path := filepath.Join(dir, kt.filename)
+		_, err = os.Stat(path)
+		if err == nil {
+			continue
+		}
+		if !os.IsNotExist(err) {
+			return
+		}
+		log.Infof("Generating %s ssh host key %s...", kt.name, path)
+		var block *pem.Block
+		block, err = kt.generate()
+		if err != nil {
+			return
+		}
+		err = ioutil.WriteFile(path, pem.EncodeToMemory(block), 0600)
+		if err != nil {
+			return
+		}
+		created = append(created, path)
+	}
+	return created, nil
+}
+
+// Load returns the host keys in dir. Missing keys are generated first.
+func Load(dir string) ([]ssh.Signer, error) {
+	_, err := Generate(dir)
+	if err != nil {
+		return nil, err
+	}
+	signers := []ssh.Signer{}
+	for _, kt := range keyTypes {
+		signer, err := LoadFile(filepath.Join(dir, kt.filename))
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
+// LoadFile reads a single private key. It supports the PEM formats and the OpenSSH format.
+func LoadFile(path string) (ssh.Signer, error) {
+	privateBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.ParsePrivateKey(privateBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %s", path, err.Error())
+	}
+	log.Debugf("Loaded ssh host key %s (%s)", path, ssh.FingerprintSHA256(signer.PublicKey()))
+	return signer, nil
+}
+
+func generateRSA() (*pem.Block, error) {
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	if err != nil {
+		return nil, err
+	}
+	return &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}, nil
+}
+
+func generateECDSA() (*pem.Block, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	data, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &pem.Block{Type: "EC PRIVATE KEY", Bytes: data}, nil
+}
+
+// There's no standard PEM format for ed25519 keys that every Go version can read, so we write them in the
+// OpenSSH format. See: https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.key
+func generateED25519() (*pem.Block, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// the check bytes are random, they're only used to verify the decryption of encrypted keys
+	var check [4]byte
+	_, err = rand.Read(check[:])
+	if err != nil {
+		return nil, err
+	}
+	checkInt := binary.BigEndian.Uint32(check[:])
+	privateBlock := struct {
+		Check1  uint32
+		Check2  uint32
+		Keytype string
+		Pub     []byte
+		Priv    []byte
+		Comment string
+	}{
+		Check1:  checkInt,
+		Check2:  checkInt,
+		Keytype: ssh.KeyAlgoED25519,
+		Pub:     publicKey,
+		Priv:    privateKey,
+		Comment: keyComment,
+	}
+	blockData := ssh.Marshal(privateBlock)
+	// pad to the cipher block size, 8 for unencrypted keys
+	for i := 1; len(blockData)%8 != 0; i++ {
+		blockData = append(blockData, byte(i))
+	}
+
+	key := struct {
+		CipherName   string
+		KdfName      string
+		KdfOpts      string
+		NumKeys      uint32
+		PubKey       []byte
+		PrivKeyBlock []byte
+	}{
+		CipherName:   "none",
+		KdfName:      "none",
+		NumKeys:      1,
+		PubKey:       sshPublicKey.Marshal(),
+		PrivKeyBlock: blockData,
+	}
+	data := append([]byte("openssh-key-v1\x00"), ssh.Marshal(key)...)
+	return &pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: data}, nil
+}
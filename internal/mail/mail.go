@@ -0,0 +1,199 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeroenjacobs79/tobw/internal/config"
+)
+
+// Mailer sends a plain text email.
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+// maximum time we wait for the smtp server
+const smtpTimeout = 30 * time.Second
+
+// the mailer used by the game, nil if we can't send email
+var mailer Mailer
+
+// Open sets up the mailer in the configuration.
+func Open(mailConfig config.MailConfig) error {
+	switch mailConfig.Type {
+	case config.MailNone:
+		mailer = nil
+	case config.MailSMTP:
+		mailer = &SMTPMailer{
+			Host:     mailConfig.Host,
+			Port:     mailConfig.Port,
+			Username: mailConfig.Username,
+			Password: mailConfig.Password,
+			From:     mailConfig.From,
+		}
+	case config.MailFile:
+		err := os.MkdirAll(mailConfig.Dir, 0700)
+		if err != nil {
+			return err
+		}
+		mailer = &FileMailer{
+			Dir:  mailConfig.Dir,
+			From: mailConfig.From,
+		}
+	default:
+		return fmt.Errorf("Unknown mail type: %s", mailConfig.Type)
+	}
+	return nil
+}
+
+// Enabled returns true if we can send email.
+func Enabled() bool {
+	return mailer != nil
+}
+
+// Send sends an email with the configured mailer.
+func Send(to string, subject string, body string) error {
+	if mailer == nil {
+		return fmt.Errorf("No mailer is configured")
+	}
+	return mailer.Send(to, subject, body)
+}
+
+// SMTPMailer sends email through an smtp server. The connection is encrypted with STARTTLS if the server supports it,
+// authentication is only done over encrypted connections.
+type SMTPMailer struct {
+	Host     string
+	Port     uint16
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(to string, subject string, body string) (err error) {
+	message, err := buildMessage(m.From, to, subject, body)
+	if err != nil {
+		return
+	}
+	// the from address can contain a name, the envelope only has the address
+	sender, err := netmail.ParseAddress(m.From)
+	if err != nil {
+		return
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(m.Host, strconv.Itoa(int(m.Port))), smtpTimeout)
+	if err != nil {
+		return
+	}
+	_ = conn.SetDeadline(time.Now().Add(smtpTimeout))
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: m.Host})
+		if err != nil {
+			return
+		}
+	}
+	if m.Username != "" {
+		err = client.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host))
+		if err != nil {
+			return
+		}
+	}
+	err = client.Mail(sender.Address)
+	if err != nil {
+		return
+	}
+	err = client.Rcpt(to)
+	if err != nil {
+		return
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return
+	}
+	_, err = writer.Write(message)
+	if err != nil {
+		return
+	}
+	err = writer.Close()
+	if err != nil {
+		return
+	}
+	return client.Quit()
+}
+
+// FileMailer writes every email to a file in a directory, instead of sending it. Useful for development and tests.
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (m *FileMailer) Send(to string, subject string, body string) error {
+	message, err := buildMessage(m.From, to, subject, body)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), randomID())
+	return ioutil.WriteFile(filepath.Join(m.Dir, name), message, 0600)
+}
+
+// builds a plain text message with the headers most servers expect.
+func buildMessage(from string, to string, subject string, body string) ([]byte, error) {
+	for _, header := range []string{from, to, subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, fmt.Errorf("Invalid mail header: %q", header)
+		}
+	}
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = strings.Trim(from[i+1:], "<> ")
+	}
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", to)
+	fmt.Fprintf(&message, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "Message-ID: <%s@%s>\r\n", randomID(), domain)
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(strings.NewReplacer("\r\n", "\r\n", "\n", "\r\n").Replace(body))
+	return message.Bytes(), nil
+}
+
+func randomID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -46,6 +46,36 @@ var (
 		Name: "tobw_current_connections_raw",
 		Help: "The number of current connections over raw tcp",
 	})
+	CurrentWebSocketConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "tobw_current_connections_websocket",
+		Help: "The number of current connections over websocket",
+	})
+	CurrentRLoginConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "tobw_current_connections_rlogin",
+		Help: "The number of current connections over rlogin",
+	})
+
+	TelnetCompressionBytesSaved = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tobw_telnet_compression_saved_bytes_total",
+		Help: "The number of bytes saved by MCCP compression on telnet connections",
+	})
+
+	FailedLogins = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tobw_failed_logins_total",
+		Help: "The number of login attempts with a wrong password or code",
+	})
+	ThrottledLogins = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tobw_throttled_logins_total",
+		Help: "The number of login attempts refused because of earlier failures",
+	})
+	AccountLockouts = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tobw_account_lockouts_total",
+		Help: "The number of times an account was locked because of failed logins",
+	})
+	RejectedConnections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tobw_rejected_connections_total",
+		Help: "The number of connections that were refused, by reason",
+	}, []string{"reason"})
 )
 
 func StartMetricsEndpoint(config config.PrometheusConfig) {
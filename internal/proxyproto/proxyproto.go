@@ -0,0 +1,245 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PROXY protocol (version 1 and 2) as used by HAProxy and most TCP load balancers.
+// See: https://www.haproxy.org/download/1.8/doc/proxy-protocol.txt
+//
+// The load balancer sends a header with the address of the client before any other data. The header is read on the
+// first Read, Write or RemoteAddr call, so Accept doesn't block on slow clients.
+
+const (
+	// maximum length of a v1 header, including CRLF
+	maxV1HeaderLength = 107
+
+	v2CommandLocal byte = 0x00
+	v2CommandProxy byte = 0x01
+
+	v2FamilyTCP4 byte = 0x11
+	v2FamilyTCP6 byte = 0x21
+)
+
+var (
+	v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+	ErrInvalidHeader = errors.New("proxyproto: invalid PROXY protocol header")
+)
+
+type Listener struct {
+	net.Listener
+	// maximum time to wait for the header
+	Timeout time.Duration
+}
+
+// NewListener expects a PROXY protocol header on every connection accepted by l.
+func NewListener(l net.Listener, timeout time.Duration) *Listener {
+	return &Listener{Listener: l, Timeout: timeout}
+}
+
+func (l *Listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{
+		Conn:    conn,
+		in:      bufio.NewReader(conn),
+		timeout: l.Timeout,
+	}, nil
+}
+
+type Conn struct {
+	net.Conn
+	in         *bufio.Reader
+	timeout    time.Duration
+	headerOnce sync.Once
+	headerErr  error
+	remoteAddr net.Addr
+	// read deadline set by the user of the connection, restored after reading the header
+	deadlineLock sync.Mutex
+	readDeadline time.Time
+}
+
+func (c *Conn) Read(data []byte) (int, error) {
+	err := c.readHeader()
+	if err != nil {
+		return 0, err
+	}
+	return c.in.Read(data)
+}
+
+func (c *Conn) Write(data []byte) (int, error) {
+	err := c.readHeader()
+	if err != nil {
+		return 0, err
+	}
+	return c.Conn.Write(data)
+}
+
+// RemoteAddr returns the address of the client, as reported by the load balancer.
+func (c *Conn) RemoteAddr() net.Addr {
+	err := c.readHeader()
+	if err != nil || c.remoteAddr == nil {
+		return c.Conn.RemoteAddr()
+	}
+	return c.remoteAddr
+}
+
+// NetConn returns the connection with the load balancer.
+func (c *Conn) NetConn() net.Conn {
+	return c.Conn
+}
+
+func (c *Conn) SetDeadline(t time.Time) error {
+	c.deadlineLock.Lock()
+	c.readDeadline = t
+	c.deadlineLock.Unlock()
+	return c.Conn.SetDeadline(t)
+}
+
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.deadlineLock.Lock()
+	c.readDeadline = t
+	c.deadlineLock.Unlock()
+	return c.Conn.SetReadDeadline(t)
+}
+
+func (c *Conn) readHeader() error {
+	c.headerOnce.Do(func() {
+		err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+		if err == nil {
+			c.remoteAddr, err = parseHeader(c.in)
+		}
+		if err != nil {
+			c.headerErr = fmt.Errorf("proxyproto: failed to read header from %s: %s", c.Conn.RemoteAddr(), err.Error())
+			log.Infoln(c.headerErr.Error())
+			_ = c.Conn.Close()
+			return
+		}
+		c.deadlineLock.Lock()
+		defer c.deadlineLock.Unlock()
+		c.headerErr = c.Conn.SetReadDeadline(c.readDeadline)
+	})
+	return c.headerErr
+}
+
+// returns the address of the client, or nil if the header doesn't contain one (LOCAL or UNKNOWN connections).
+func parseHeader(in *bufio.Reader) (net.Addr, error) {
+	signature, err := in.Peek(len(v2Signature))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(signature, v2Signature) {
+		return parseV2(in)
+	}
+	return parseV1(in)
+}
+
+// PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\n
+func parseV1(in *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		b, err := in.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+		if len(line) > maxV1HeaderLength {
+			return nil, ErrInvalidHeader
+		}
+	}
+	fields := strings.Split(strings.TrimSuffix(string(line), "\r\n"), " ")
+	if len(fields) < 2 || fields[0] != "PROXY" {
+		return nil, ErrInvalidHeader
+	}
+	switch fields[1] {
+	case "UNKNOWN":
+		return nil, nil
+	case "TCP4", "TCP6":
+		if len(fields) != 6 {
+			return nil, ErrInvalidHeader
+		}
+		ip := net.ParseIP(fields[2])
+		port, err := strconv.ParseUint(fields[4], 10, 16)
+		if ip == nil || err != nil {
+			return nil, ErrInvalidHeader
+		}
+		return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+	default:
+		return nil, ErrInvalidHeader
+	}
+}
+
+func parseV2(in *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, len(v2Signature)+4)
+	_, err := io.ReadFull(in, header)
+	if err != nil {
+		return nil, err
+	}
+	versionCommand := header[12]
+	family := header[13]
+	length := binary.BigEndian.Uint16(header[14:16])
+	if versionCommand>>4 != 2 {
+		return nil, ErrInvalidHeader
+	}
+	// the address block can contain extra TLVs, we don't need them but we have to read them
+	addresses := make([]byte, length)
+	_, err = io.ReadFull(in, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	switch versionCommand & 0x0f {
+	case v2CommandLocal:
+		// health checks of the load balancer itself
+		return nil, nil
+	case v2CommandProxy:
+	default:
+		return nil, ErrInvalidHeader
+	}
+	switch family {
+	case v2FamilyTCP4:
+		if len(addresses) < 12 {
+			return nil, ErrInvalidHeader
+		}
+		return &net.TCPAddr{IP: net.IP(addresses[0:4]), Port: int(binary.BigEndian.Uint16(addresses[8:10]))}, nil
+	case v2FamilyTCP6:
+		if len(addresses) < 36 {
+			return nil, ErrInvalidHeader
+		}
+		return &net.TCPAddr{IP: net.IP(addresses[0:16]), Port: int(binary.BigEndian.Uint16(addresses[32:34]))}, nil
+	default:
+		// unix sockets and other families don't have a useful address
+		return nil, nil
+	}
+}
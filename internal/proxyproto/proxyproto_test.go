@@ -0,0 +1,174 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package proxyproto
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parses a header followed by session data, and checks the data is left for the session
+func parse(t *testing.T, header string) (net.Addr, error) {
+	in := bufio.NewReader(strings.NewReader(header + "data"))
+	addr, err := parseHeader(in)
+	if err == nil {
+		rest, _ := ioutil.ReadAll(in)
+		if string(rest) != "data" {
+			t.Errorf("data after the header: %q", rest)
+		}
+	}
+	return addr, err
+}
+
+func TestParseV1(t *testing.T) {
+	valid := map[string]string{
+		"PROXY TCP4 192.0.2.1 192.0.2.11 56324 443\r\n":    "192.0.2.1:56324",
+		"PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n": "[2001:db8::1]:56324",
+		"PROXY UNKNOWN\r\n": "",
+		"PROXY UNKNOWN 192.0.2.1 192.0.2.11 56324 443\r\n":  "",
+		"PROXY UNKNOWN " + strings.Repeat("x", 91) + "\r\n": "",
+	}
+	for header, want := range valid {
+		addr, err := parse(t, header)
+		if err != nil {
+			t.Errorf("%q: %s", header, err.Error())
+			continue
+		}
+		if (addr == nil && want != "") || (addr != nil && addr.String() != want) {
+			t.Errorf("%q: address %v, want %q", header, addr, want)
+		}
+	}
+
+	invalid := []string{
+		"GET / HTTP/1.1\r\n",
+		"PROXY TCP4\r\n",
+		"PROXY UDP4 192.0.2.1 192.0.2.11 56324 443\r\n",
+		"PROXY TCP4 192.0.2.1 192.0.2.11 56324\r\n",
+		"PROXY TCP4 192.0.2.300 192.0.2.11 56324 443\r\n",
+		"PROXY TCP4 192.0.2.1 192.0.2.11 65536 443\r\n",
+		"PROXY UNKNOWN " + strings.Repeat("x", 92) + "\r\n",
+	}
+	for _, header := range invalid {
+		if _, err := parse(t, header); err != ErrInvalidHeader {
+			t.Errorf("%q: error %v, want %v", header, err, ErrInvalidHeader)
+		}
+	}
+
+	if _, err := parseHeader(bufio.NewReader(strings.NewReader("PROXY TCP4 192.0.2.1 192.0.2.11 56324 443"))); err == nil {
+		t.Error("header without CRLF accepted")
+	}
+}
+
+func TestParseV2(t *testing.T) {
+	const signature = "\r\n\r\n\x00\r\nQUIT\n"
+	// addresses and ports of a connection from 192.0.2.1:56324 to 192.0.2.11:443
+	const tcp4 = "\xc0\x00\x02\x01" + "\xc0\x00\x02\x0b" + "\xdc\x04" + "\x01\xbb"
+	// from [2001:db8::1]:56324 to [2001:db8::2]:443
+	const tcp6 = "\x20\x01\x0d\xb8" + "\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01" +
+		"\x20\x01\x0d\xb8" + "\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02" + "\xdc\x04" + "\x01\xbb"
+	tests := []struct {
+		name    string
+		header  string
+		want    string
+		wantErr bool
+	}{
+		{"TCP4", signature + "\x21\x11\x00\x0c" + tcp4, "192.0.2.1:56324", false},
+		{"TCP6", signature + "\x21\x21\x00\x24" + tcp6, "[2001:db8::1]:56324", false},
+		// a NOOP TLV after the addresses
+		{"TCP4 with TLV", signature + "\x21\x11\x00\x10" + tcp4 + "\x04\x00\x01\x00", "192.0.2.1:56324", false},
+		{"LOCAL", signature + "\x20\x00\x00\x00", "", false},
+		{"unix socket", signature + "\x21\x31\x00\xd8" + strings.Repeat("\x00", 216), "", false},
+		{"version 1", signature + "\x11\x11\x00\x0c" + tcp4, "", true},
+		{"unknown command", signature + "\x22\x11\x00\x0c" + tcp4, "", true},
+		{"TCP4 too short", signature + "\x21\x11\x00\x08" + tcp4[:8], "", true},
+		{"TCP6 too short", signature + "\x21\x21\x00\x20" + tcp6[:32], "", true},
+		{"length beyond the data", signature + "\x21\x11\x00\xff" + tcp4, "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			in := bufio.NewReader(strings.NewReader(test.header))
+			addr, err := parseHeader(in)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("address %v, want an error", addr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (addr == nil && test.want != "") || (addr != nil && addr.String() != test.want) {
+				t.Errorf("address %v, want %q", addr, test.want)
+			}
+		})
+	}
+}
+
+func TestListener(t *testing.T) {
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewListener(tcpListener, time.Second)
+	defer l.Close()
+
+	go func() {
+		client, err := net.Dial("tcp", tcpListener.Addr().String())
+		if err != nil {
+			return
+		}
+		defer client.Close()
+		client.Write([]byte("PROXY TCP4 192.0.2.1 192.0.2.11 56324 443\r\nhello"))
+		ioutil.ReadAll(client)
+	}()
+
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if addr := conn.RemoteAddr().String(); addr != "192.0.2.1:56324" {
+		t.Errorf("remote address %s, want 192.0.2.1:56324", addr)
+	}
+	data := make([]byte, 5)
+	_, err = io.ReadFull(conn, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("read %q, want %q", data, "hello")
+	}
+}
+
+// a client that doesn't send a header is disconnected after the timeout
+func TestHeaderTimeout(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	conn := &Conn{Conn: server, in: bufio.NewReader(server), timeout: 50 * time.Millisecond}
+	start := time.Now()
+	_, err := conn.Read(make([]byte, 1))
+	if err == nil {
+		t.Fatal("read without a header succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("gave up after %s", elapsed)
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package rlogin
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RLogin (RFC 1282). The client starts with the names of the users and the terminal type, all terminated by a NUL:
+//
+//   \0 client-user \0 server-user \0 terminal-type/speed \0
+//
+// After that, everything is data, except the window size message: FF FF 's' 's' followed by rows, columns,
+// x pixels and y pixels as 16-bit big-endian values. Clients only send it after the server asked for it, with a 0x80
+// byte sent as TCP urgent data.
+
+const (
+	// maximum length of a single handshake field
+	maxFieldLength = 256
+	// length of the window size message, including the magic cookie
+	windowSizeLength = 12
+	// control byte that asks the client to send window size messages
+	windowSizeRequest byte = 0x80
+)
+
+var (
+	windowSizeCookie = []byte{0xff, 0xff, 's', 's'}
+
+	ErrInvalidHandshake  = errors.New("rlogin: invalid handshake")
+	errUrgentUnsupported = errors.New("rlogin: urgent data is not supported on this connection")
+)
+
+// connections that wrap another connection, like the connections of the PROXY protocol listener
+type wrappedConn interface {
+	NetConn() net.Conn
+}
+
+type Conn struct {
+	net.Conn
+	in *bufio.Reader
+	// user on the client machine, user we should login as, and terminal type and speed
+	ClientUser    string
+	ServerUser    string
+	TerminalType  string
+	TerminalSpeed string
+	resizeHandler func(int, int)
+	// bytes that might be the start of a window size message
+	windowBuffer []byte
+	pending      []byte
+}
+
+func NewConnection(c net.Conn) *Conn {
+	return &Conn{
+		Conn: c,
+		in:   bufio.NewReader(c),
+	}
+}
+
+// Handshake reads the user names and terminal type sent by the client, and acknowledges them.
+func (c *Conn) Handshake(timeout time.Duration) error {
+	err := c.SetReadDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return err
+	}
+	fields := make([]string, 4)
+	for i := range fields {
+		fields[i], err = c.readField()
+		if err != nil {
+			return err
+		}
+	}
+	// the first field is always empty
+	if fields[0] != "" {
+		return ErrInvalidHandshake
+	}
+	c.ClientUser = fields[1]
+	c.ServerUser = fields[2]
+	c.TerminalType = fields[3]
+	if slash := strings.Index(fields[3], "/"); slash >= 0 {
+		c.TerminalType = fields[3][:slash]
+		c.TerminalSpeed = fields[3][slash+1:]
+	}
+	log.Debugf("%s - rlogin handshake received (client user: %s, server user: %s, terminal: %s, speed: %s)", c.RemoteAddr(), c.ClientUser, c.ServerUser, c.TerminalType, c.TerminalSpeed)
+	err = c.SetReadDeadline(time.Time{})
+	if err != nil {
+		return err
+	}
+	_, err = c.Conn.Write([]byte{0})
+	if err != nil {
+		return err
+	}
+	// without this, the client never tells us the size of its window
+	conn := c.Conn
+	for {
+		wrapped, ok := conn.(wrappedConn)
+		if !ok {
+			break
+		}
+		conn = wrapped.NetConn()
+	}
+	err = sendUrgent(conn, windowSizeRequest)
+	if err != nil {
+		log.Debugf("%s - Can't ask for the window size: %s", c.RemoteAddr(), err.Error())
+	}
+	return nil
+}
+
+func (c *Conn) readField() (string, error) {
+	var field []byte
+	for {
+		b, err := c.in.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if b == 0 {
+			return string(field), nil
+		}
+		if len(field) >= maxFieldLength {
+			return "", ErrInvalidHandshake
+		}
+		field = append(field, b)
+	}
+}
+
+func (c *Conn) InstallResizeHandler(handler func(int, int)) {
+	c.resizeHandler = handler
+}
+
+// Read returns the data sent by the client, without the window size messages.
+func (c *Conn) Read(data []byte) (int, error) {
+	for len(c.pending) == 0 {
+		buffer := make([]byte, len(data))
+		n, err := c.in.Read(buffer)
+		for _, b := range buffer[:n] {
+			c.processByte(b)
+		}
+		if err != nil {
+			if len(c.pending) > 0 {
+				break
+			}
+			return 0, err
+		}
+	}
+	n := copy(data, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+func (c *Conn) processByte(b byte) {
+	c.windowBuffer = append(c.windowBuffer, b)
+	index := len(c.windowBuffer) - 1
+	if index < len(windowSizeCookie) && b != windowSizeCookie[index] {
+		// not a window size message after all, it's just data
+		c.pending = append(c.pending, c.windowBuffer...)
+		c.windowBuffer = c.windowBuffer[:0]
+		return
+	}
+	if len(c.windowBuffer) < windowSizeLength {
+		return
+	}
+	rows := binary.BigEndian.Uint16(c.windowBuffer[4:6])
+	columns := binary.BigEndian.Uint16(c.windowBuffer[6:8])
+	c.windowBuffer = c.windowBuffer[:0]
+	log.Debugf("%s - terminal size update received (w=%d, h=%d)", c.RemoteAddr(), columns, rows)
+	if c.resizeHandler != nil && columns > 0 && rows > 0 {
+		c.resizeHandler(int(columns), int(rows))
+	}
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeroenjacobs79/tobw/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// The session manager keeps track of all running sessions. Like on a BBS, every session gets a node number: the lowest
+// number that isn't in use. The maximum number of nodes is configurable.
+
+var (
+	ErrNoFreeNodes = errors.New("all nodes are in use")
+	ErrUnknownNode = errors.New("no session on this node")
+)
+
+var (
+	activeSessions = make(map[int]*TerminalSession)
+	activeLock     sync.Mutex
+	activeWait     sync.WaitGroup
+)
+
+// Info describes a running session, for who's online lists and admin tools.
+type Info struct {
+	Node        int
+	Handle      string
+	Protocol    config.ConnectionType
+	Origin      string
+	ConnectedAt time.Time
+	Location    string
+}
+
+// assigns a node number to the session
+func register(session *TerminalSession) error {
+	activeLock.Lock()
+	defer activeLock.Unlock()
+	node := 1
+	for ; ; node++ {
+		if _, ok := activeSessions[node]; !ok {
+			break
+		}
+	}
+	maxNodes := config.AppOptions.MaxNodes
+	if maxNodes > 0 && node > maxNodes {
+		return ErrNoFreeNodes
+	}
+	session.Node = node
+	session.ConnectedAt = time.Now()
+	activeSessions[node] = session
+	activeWait.Add(1)
+	log.Debugf("%s - Session started on node %d", session.OriginAddress, node)
+	return nil
+}
+
+func unregister(session *TerminalSession) {
+	LeaveRoom(session)
+	err := session.Save()
+	if err != nil {
+		log.Errorf("%s - Failed to save session: %s", session.OriginAddress, err.Error())
+	}
+	activeLock.Lock()
+	defer activeLock.Unlock()
+	if activeSessions[session.Node] == session {
+		delete(activeSessions, session.Node)
+		activeWait.Done()
+	}
+}
+
+// Sessions returns all running sessions, ordered by node number.
+func Sessions() []*TerminalSession {
+	activeLock.Lock()
+	defer activeLock.Unlock()
+	result := make([]*TerminalSession, 0, len(activeSessions))
+	for _, s := range activeSessions {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Node < result[j].Node
+	})
+	return result
+}
+
+// List returns information about all running sessions, ordered by node number.
+func List() []Info {
+	sessions := Sessions()
+	result := make([]Info, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, s.Info())
+	}
+	return result
+}
+
+// FindByNode returns the session on the node, or nil if the node isn't in use.
+func FindByNode(node int) *TerminalSession {
+	activeLock.Lock()
+	defer activeLock.Unlock()
+	return activeSessions[node]
+}
+
+// FindByHandle returns the session of the player with the handle, or nil if the player isn't online.
+func FindByHandle(handle string) *TerminalSession {
+	for _, s := range Sessions() {
+		if strings.EqualFold(s.Handle(), handle) {
+			return s
+		}
+	}
+	return nil
+}
+
+// Kick disconnects the session on the node. The reason is shown to the player.
+func Kick(node int, reason string) error {
+	s := FindByNode(node)
+	if s == nil {
+		return ErrUnknownNode
+	}
+	log.Infof("%s - Kicked from node %d: %s", s.OriginAddress, node, reason)
+	err := s.Terminal.WriteMessage(fmt.Sprintf("\n\n*** You have been disconnected: %s ***\n", reason))
+	if err != nil {
+		log.Debugf("%s - Failed to send kick message: %s", s.OriginAddress, err.Error())
+	}
+	s.Hangup()
+	return nil
+}
+
+// ActiveSessions returns the number of running sessions.
+func ActiveSessions() int {
+	activeLock.Lock()
+	defer activeLock.Unlock()
+	return len(activeSessions)
+}
+
+// Broadcast sends a message to every connected player. Like pages, it is shown while the session waits for input.
+func Broadcast(message string) {
+	for _, s := range Sessions() {
+		if !s.Terminal.Notify(message) {
+			log.Debugf("%s - Dropped broadcast, too many unread messages", s.OriginAddress)
+		}
+	}
+}
+
+// BroadcastNow writes a message to every connected player right away, even if they are typing. Use this for the
+// last message before sessions are hung up.
+func BroadcastNow(message string) {
+	for _, s := range Sessions() {
+		err := s.Terminal.WriteMessage(message)
+		if err != nil {
+			log.Debugf("%s - Failed to send broadcast: %s", s.OriginAddress, err.Error())
+		}
+	}
+}
+
+// SaveAll stores the state of every player that is logged in.
+func SaveAll() {
+	for _, s := range Sessions() {
+		err := s.Save()
+		if err != nil {
+			log.Errorf("%s - Failed to save session: %s", s.OriginAddress, err.Error())
+		}
+	}
+}
+
+// Wait blocks until all sessions have ended, or until the timeout expires. Returns false on timeout.
+func Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		activeWait.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
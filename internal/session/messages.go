@@ -0,0 +1,158 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Players can page each other and talk in chat rooms. Messages are queued on the terminal of the receiving player, and
+// shown while that session waits for input, so they never end up in the middle of an input field.
+
+var (
+	ErrNotOnline   = errors.New("player is not online")
+	ErrQueueFull   = errors.New("player has too many unread messages")
+	ErrUnknownRoom = errors.New("unknown chat room")
+	ErrNotInRoom   = errors.New("not in a chat room")
+)
+
+// Room is a place in the game where players can chat.
+type Room struct {
+	Name    string
+	members map[*TerminalSession]struct{}
+}
+
+var (
+	rooms = []*Room{
+		{Name: "The Inn"},
+		{Name: "Dark Cloak Tavern"},
+	}
+	// the room every session is in
+	roomOf   = make(map[*TerminalSession]*Room)
+	roomLock sync.Mutex
+)
+
+// FindRoom returns the chat room with the name, or nil if there is no such room.
+func FindRoom(name string) *Room {
+	for _, room := range rooms {
+		if strings.EqualFold(room.Name, name) {
+			return room
+		}
+	}
+	return nil
+}
+
+// Page sends a private message to a player that is online. The player is found by handle.
+func Page(from *TerminalSession, to string, text string) error {
+	target := FindByHandle(to)
+	if target == nil {
+		return ErrNotOnline
+	}
+	log.Debugf("%s - Page from %s to %s", from.OriginAddress, from.Username(), target.Username())
+	if !target.Terminal.Notify(fmt.Sprintf("\x1B[0;1;33m*** Page from %s: \x1B[0;22;37m%s", from.Handle(), cleanMessage(text))) {
+		return ErrQueueFull
+	}
+	return nil
+}
+
+// JoinRoom moves the session to a chat room. It leaves the room it was in.
+func JoinRoom(session *TerminalSession, name string) error {
+	room := FindRoom(name)
+	if room == nil {
+		return ErrUnknownRoom
+	}
+	LeaveRoom(session)
+	roomLock.Lock()
+	defer roomLock.Unlock()
+	if room.members == nil {
+		room.members = make(map[*TerminalSession]struct{})
+	}
+	room.members[session] = struct{}{}
+	roomOf[session] = room
+	notifyRoom(room, session, fmt.Sprintf("\x1B[0;22;36m%s enters %s.", session.Handle(), room.Name))
+	return nil
+}
+
+// LeaveRoom removes the session from the chat room it is in, if any.
+func LeaveRoom(session *TerminalSession) {
+	roomLock.Lock()
+	defer roomLock.Unlock()
+	room := roomOf[session]
+	if room == nil {
+		return
+	}
+	delete(room.members, session)
+	delete(roomOf, session)
+	notifyRoom(room, session, fmt.Sprintf("\x1B[0;22;36m%s leaves %s.", session.Handle(), room.Name))
+}
+
+// Say sends a message to everyone else in the chat room of the session.
+func Say(session *TerminalSession, text string) error {
+	roomLock.Lock()
+	defer roomLock.Unlock()
+	room := roomOf[session]
+	if room == nil {
+		return ErrNotInRoom
+	}
+	notifyRoom(room, session, fmt.Sprintf("\x1B[0;1;32m%s: \x1B[0;22;32m%s", session.Handle(), cleanMessage(text)))
+	return nil
+}
+
+// RoomMembers returns the handles of the players in a chat room, sorted by handle.
+func RoomMembers(name string) []string {
+	room := FindRoom(name)
+	if room == nil {
+		return nil
+	}
+	roomLock.Lock()
+	defer roomLock.Unlock()
+	result := make([]string, 0, len(room.members))
+	for s := range room.members {
+		result = append(result, s.Handle())
+	}
+	sort.Strings(result)
+	return result
+}
+
+// sends a message to all members of the room, except the sender. Call with roomLock held.
+func notifyRoom(room *Room, sender *TerminalSession, message string) {
+	for s := range room.members {
+		if s == sender {
+			continue
+		}
+		if !s.Terminal.Notify(message) {
+			log.Debugf("%s - Dropped chat message, too many unread messages", s.OriginAddress)
+		}
+	}
+}
+
+// players can't send escape sequences or other control characters to each other.
+func cleanMessage(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsPrint(r) {
+			return r
+		}
+		return -1
+	}, text)
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package session
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/jeroenjacobs79/tobw/internal/ansiterm"
+	"github.com/jeroenjacobs79/tobw/internal/mail"
+	"github.com/jeroenjacobs79/tobw/internal/user"
+	log "github.com/sirupsen/logrus"
+)
+
+// New players create their account by typing NEW at the login prompt.
+
+const (
+	// times a player can enter an invalid value before we give up
+	maxRegistrationAttempts = 3
+	verificationCodeLength  = 6
+	verificationCodeExpiry  = 15 * time.Minute
+	// same size as the field on the login prompt
+	passwordFieldSize = 32
+)
+
+// asks for a value until it passes the check. Returns an empty string if the player doesn't enter anything, or enters
+// too many invalid values.
+func askValue(term *ansiterm.AnsiTerminal, prompt string, size int, mode ansiterm.InputMode, value string, check func(string) error) (string, error) {
+	for attempt := 0; attempt < maxRegistrationAttempts; attempt++ {
+		term.SetColor(ansiterm.White, false)
+		term.Print(prompt)
+		result, err := term.InputDefault(size, mode, value)
+		if err != nil {
+			return "", err
+		}
+		if result == "" {
+			return "", nil
+		}
+		err = check(result)
+		if err == nil {
+			return result, nil
+		}
+		term.SetColor(ansiterm.Red, true)
+		term.Println(err.Error())
+		value = ""
+	}
+	return "", nil
+}
+
+// registerUser creates a new account. Returns nil if the player gave up, or if the account couldn't be created.
+func registerUser(session *TerminalSession) *user.User {
+	session.SetLocation("Registration")
+	term := session.Terminal
+	term.SetColor(ansiterm.White, true)
+	term.Println("\nWelcome, stranger! Tell us who you are. Press enter on an empty field to cancel.")
+
+	username, err := askValue(term, "\nUsername: ", user.MaxUsernameLength, ansiterm.InputUpfirst, "", func(value string) error {
+		err := user.ValidateUsername(value)
+		if err == nil && user.Find(value) != nil {
+			err = fmt.Errorf("This username is already taken.")
+		}
+		return err
+	})
+	if err != nil || username == "" {
+		return nil
+	}
+
+	term.SetColor(ansiterm.Green, false)
+	term.Println("\nYour handle is the name other players see.")
+	handle, err := askValue(term, "Handle: ", user.MaxHandleLength, ansiterm.InputAll, username, func(value string) error {
+		err := user.ValidateHandle(value)
+		if err == nil && user.FindByHandle(value) != nil {
+			err = fmt.Errorf("This handle is already in use.")
+		}
+		return err
+	})
+	if err != nil || handle == "" {
+		return nil
+	}
+
+	email, err := askValue(term, "\nEmail address: ", 60, ansiterm.InputAll, "", user.ValidateEmail)
+	if err != nil || email == "" {
+		return nil
+	}
+
+	password, err := askPassword(term, username, handle)
+	if err != nil || password == "" {
+		return nil
+	}
+
+	newUser := &user.User{
+		Username: username,
+		Handle:   handle,
+		Email:    email,
+	}
+	if mail.Enabled() {
+		if !verifyEmail(session, email) {
+			return nil
+		}
+		newUser.EmailVerified = true
+	}
+	err = newUser.SetPassword(password)
+	if err == nil {
+		err = user.Register(newUser)
+	}
+	if err != nil {
+		log.Errorln(err.Error())
+		term.SetColor(ansiterm.Red, true)
+		term.Println("\nYour account couldn't be created. Please try again later.")
+		return nil
+	}
+	log.Infof("%s - New user %s registered", session.OriginAddress, newUser.Username)
+	term.SetColor(ansiterm.Green, true)
+	term.Printf("\nYour account has been created. Welcome to the realm, %s!\n", newUser.Handle)
+	return newUser
+}
+
+// asks for a strong password, twice.
+func askPassword(term *ansiterm.AnsiTerminal, username string, handle string) (string, error) {
+	for attempt := 0; attempt < maxRegistrationAttempts; attempt++ {
+		password, err := askValue(term, "\nPassword: ", passwordFieldSize, ansiterm.InputPassword, "", func(value string) error {
+			return user.CheckPasswordStrength(value, username, handle)
+		})
+		if err != nil || password == "" {
+			return "", err
+		}
+		term.SetColor(ansiterm.White, false)
+		term.Print("Repeat your password: ")
+		repeated, err := term.Input(passwordFieldSize, ansiterm.InputPassword)
+		if err != nil {
+			return "", err
+		}
+		if repeated == password {
+			return password, nil
+		}
+		term.SetColor(ansiterm.Red, true)
+		term.Println("The passwords don't match.")
+	}
+	return "", nil
+}
+
+// mails a verification code, and asks the player to enter it.
+func verifyEmail(session *TerminalSession, email string) bool {
+	term := session.Terminal
+	code, err := newVerificationCode()
+	if err == nil {
+		term.SetColor(ansiterm.Green, false)
+		term.Printf("\nSending a verification code to %s...\n", email)
+		err = mail.Send(email, "Your Tale of the Black Wyvern verification code",
+			fmt.Sprintf("Welcome to Tale of the Black Wyvern!\n\nYour verification code is: %s\n\nThe code is valid for %d minutes.\n",
+				code, int(verificationCodeExpiry.Minutes())))
+	}
+	if err != nil {
+		log.Errorf("%s - Failed to send verification code: %s", session.OriginAddress, err.Error())
+		term.SetColor(ansiterm.Red, true)
+		term.Println("We couldn't send you an email. Please try again later.")
+		return false
+	}
+	expires := time.Now().Add(verificationCodeExpiry)
+	// the player needs time to check their mail, so we wait as long as the code is valid
+	previousTimeout := term.KeyTimeout
+	term.KeyTimeout = verificationCodeExpiry
+	defer func() {
+		term.KeyTimeout = previousTimeout
+	}()
+	for attempt := 0; attempt < maxRegistrationAttempts; attempt++ {
+		term.SetColor(ansiterm.White, false)
+		term.Print("Verification code: ")
+		entered, err := term.Input(verificationCodeLength, ansiterm.InputDigit)
+		if err != nil || entered == "" {
+			return false
+		}
+		if time.Now().After(expires) {
+			term.SetColor(ansiterm.Red, true)
+			term.Println("The code has expired.")
+			return false
+		}
+		if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(entered)), []byte(code)) == 1 {
+			return true
+		}
+		term.SetColor(ansiterm.Red, true)
+		term.Println("This code is not correct.")
+	}
+	return false
+}
+
+func newVerificationCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < verificationCodeLength; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", verificationCodeLength, n), nil
+}
@@ -17,14 +17,18 @@
 package session
 
 import (
+	"context"
+	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jeroenjacobs79/tobw/internal/ansiterm"
 	"github.com/jeroenjacobs79/tobw/internal/config"
+	"github.com/jeroenjacobs79/tobw/internal/guard"
 	"github.com/jeroenjacobs79/tobw/internal/monitoring"
 	"github.com/jeroenjacobs79/tobw/internal/user"
-	"github.com/mdp/qrterminal"
+	log "github.com/sirupsen/logrus"
 )
 
 type TerminalSession struct {
@@ -32,43 +36,274 @@ type TerminalSession struct {
 	Terminal       *ansiterm.AnsiTerminal
 	ConnectionType config.ConnectionType
 	OriginAddress  string
+	// out-of-band data channel for graphical clients (GMCP/MSDP). Never nil.
+	OutOfBand OutOfBandSender
+	// environment variables sent by the client (USER, LANG, ...), guarded by infoLock. Never nil.
+	environment map[string]string
+	// terminal modes sent by ssh clients in the pty-req (RFC 4254 section 8), by opcode. Guarded by infoLock.
+	terminalModes map[byte]uint32
+	// receives an event when the client sends an interrupt (telnet IP or Break)
+	interrupts chan struct{}
+	// make sure a session is only hung up once
+	hangupOnce sync.Once
+	// the logged in user. Can be set before the session starts, if the user was already authenticated (eg: ssh).
+	User *user.User
+	// node number and time the session started, assigned by the session manager
+	Node        int
+	ConnectedAt time.Time
+	// where the player is in the game, shown in the who's online list
+	location string
+	infoLock sync.RWMutex
 }
 
-// placeholder for hangup channel, so we can use it anywhere in our package
-var hangupChannel chan<- *TerminalSession
+// OutOfBandSender is implemented by connections that can send data outside the terminal stream.
+// Telnet connections implement this with GMCP and MSDP.
+type OutOfBandSender interface {
+	SendGMCP(pkg string, payload interface{}) error
+	SendMSDP(variable string, value interface{}) error
+}
+
+// used for connections without out-of-band support, like ssh and raw tcp.
+type noOutOfBand struct{}
+
+func (noOutOfBand) SendGMCP(pkg string, payload interface{}) error {
+	return nil
+}
+
+func (noOutOfBand) SendMSDP(variable string, value interface{}) error {
+	return nil
+}
+
+// longest line we accept for a public key. RSA 4096 keys are about 750 characters.
+const maxAuthorizedKeyLength = 2048
+
+const (
+	// longest page or chat message
+	maxMessageLength = 200
+	// players in a chat room are often just reading, so we wait longer before we give up on them
+	chatKeyTimeout = 10 * time.Minute
+)
 
+// CreateSession creates a session for a connection. The connection is closed when the session ends, or when it's
+// hung up from elsewhere.
 func CreateSession(term *ansiterm.AnsiTerminal, conntype config.ConnectionType, origin string) *TerminalSession {
 	session := TerminalSession{
 		Terminal:       term,
 		ConnectionType: conntype,
 		OriginAddress:  origin,
+		OutOfBand:      noOutOfBand{},
+		environment:    make(map[string]string),
+		interrupts:     make(chan struct{}, 1),
 	}
+	term.SetInterrupts(session.interrupts)
 	return &session
 }
 
-func Start(session *TerminalSession, hangup chan<- *TerminalSession) {
+// Hangup closes the connection, which makes the session end wherever it's waiting. Can be called from anywhere
+// (eg: when a keepalive fails), and more than once. It doesn't wait until the connection is closed, output the
+// session still has buffered is dropped.
+func (s *TerminalSession) Hangup() {
+	s.hangupOnce.Do(func() {
+		// closing can block on a dead connection, so every session is closed on its own
+		go func() {
+			err := s.Terminal.Hangup()
+			if err != nil {
+				log.Debugf("%s - Hangup: %s", s.OriginAddress, err.Error())
+			}
+		}()
+	})
+}
+
+// called by Start when the session ends, from the goroutine that writes to the terminal
+func (s *TerminalSession) close() {
+	err := s.Terminal.Close()
+	log.Infof("%s - Disconnected", s.OriginAddress)
+	if err != nil {
+		log.Debugf("%s - Close: %s", s.OriginAddress, err.Error())
+	}
+}
+
+// Interrupt signals the session that the user wants to interrupt the current action.
+// It never blocks, multiple interrupts that haven't been handled yet are merged into one.
+func (s *TerminalSession) Interrupt() {
+	log.Debugf("%s - Interrupt received", s.OriginAddress)
+	select {
+	case s.interrupts <- struct{}{}:
+	default:
+	}
+}
+
+// Interrupts returns the channel on which interrupt events are received.
+func (s *TerminalSession) Interrupts() <-chan struct{} {
+	return s.interrupts
+}
+
+// TerminalType returns the terminal type reported by the client, or an empty string if unknown.
+func (s *TerminalSession) TerminalType() string {
+	return s.Terminal.GetTerminalType()
+}
+
+// Username returns the name of the logged in user, or an empty string if the user didn't login yet.
+func (s *TerminalSession) Username() string {
+	s.infoLock.RLock()
+	defer s.infoLock.RUnlock()
+	if s.User == nil {
+		return ""
+	}
+	return s.User.Username
+}
+
+// Handle returns the name other players know the logged in user by, or an empty string if nobody is logged in yet.
+func (s *TerminalSession) Handle() string {
+	s.infoLock.RLock()
+	defer s.infoLock.RUnlock()
+	if s.User == nil {
+		return ""
+	}
+	return s.User.Handle
+}
+
+func (s *TerminalSession) setUser(u *user.User) {
+	s.infoLock.Lock()
+	defer s.infoLock.Unlock()
+	s.User = u
+}
+
+// Getenv returns the value of an environment variable sent by the client, or an empty string if it wasn't sent.
+func (s *TerminalSession) Getenv(name string) string {
+	s.infoLock.RLock()
+	defer s.infoLock.RUnlock()
+	return s.environment[name]
+}
+
+// Setenv can be called by the protocol handlers while the session is running.
+func (s *TerminalSession) Setenv(name string, value string) {
+	s.infoLock.Lock()
+	defer s.infoLock.Unlock()
+	s.environment[name] = value
+}
+
+// SetEnvironment replaces all environment variables.
+func (s *TerminalSession) SetEnvironment(env map[string]string) {
+	s.infoLock.Lock()
+	defer s.infoLock.Unlock()
+	s.environment = make(map[string]string, len(env))
+	for name, value := range env {
+		s.environment[name] = value
+	}
+}
+
+// TerminalMode returns the value of a terminal mode sent by an ssh client.
+func (s *TerminalSession) TerminalMode(opcode byte) (value uint32, ok bool) {
+	s.infoLock.RLock()
+	defer s.infoLock.RUnlock()
+	value, ok = s.terminalModes[opcode]
+	return
+}
+
+// SetTerminalModes is called when an ssh client sends a pty-req, which can happen while the session is running.
+func (s *TerminalSession) SetTerminalModes(modes map[byte]uint32) {
+	s.infoLock.Lock()
+	defer s.infoLock.Unlock()
+	s.terminalModes = modes
+}
+
+// Save stores the state of the player. This happens when the session ends, and when the server shuts down.
+func (s *TerminalSession) Save() error {
+	username := s.Username()
+	if username == "" {
+		return nil
+	}
+	return user.SetLastSeen(username, time.Now())
+}
+
+// Location returns where the player is in the game.
+func (s *TerminalSession) Location() string {
+	s.infoLock.RLock()
+	defer s.infoLock.RUnlock()
+	return s.location
+}
+
+// SetLocation should be called when the player moves to another part of the game.
+func (s *TerminalSession) SetLocation(location string) {
+	s.infoLock.Lock()
+	defer s.infoLock.Unlock()
+	s.location = location
+}
+
+// Info returns a snapshot of the session, for who's online lists.
+func (s *TerminalSession) Info() Info {
+	return Info{
+		Node:        s.Node,
+		Handle:      s.Handle(),
+		Protocol:    s.ConnectionType,
+		Origin:      s.OriginAddress,
+		ConnectedAt: s.ConnectedAt,
+		Location:    s.Location(),
+	}
+}
+
+// counts a session in the connection metrics, use 1 when it starts and -1 when it ends.
+func countConnection(connType config.ConnectionType, delta float64) {
+	monitoring.CurrentConnections.Add(delta)
+	switch connType {
+	case config.TCPRaw:
+		monitoring.CurrentRawConnections.Add(delta)
+	case config.TCPTelnet, config.TCPTelnets:
+		monitoring.CurrentTelnetConnections.Add(delta)
+	case config.TCPSSH:
+		monitoring.CurrentSSHConnections.Add(delta)
+	case config.TCPWebSocket:
+		monitoring.CurrentWebSocketConnections.Add(delta)
+	case config.TCPRLogin:
+		monitoring.CurrentRLoginConnections.Add(delta)
+	default:
+		log.Errorf("Unknown terminal-type detected!")
+	}
+}
+
+// HasCapability can be used to decide on UTF-8 vs CP437 output, 256-color support etc...
+func (s *TerminalSession) HasCapability(c ansiterm.Capability) bool {
+	return s.Terminal.HasCapability(c)
+}
+
+// Start runs the session until the player leaves, or until ctx is done (eg: the server shuts down).
+func Start(ctx context.Context, session *TerminalSession) {
+	// send what is still buffered, and close the connection at the end
+	defer session.close()
+
+	// set metrics. Do this before anything else, so they're also decreased when the session is cancelled early.
+	countConnection(session.ConnectionType, 1)
+	defer countConnection(session.ConnectionType, -1)
+
+	// hanging up makes all pending reads fail, so the session ends wherever it's waiting for the player.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			session.Hangup()
+		case <-done:
+		}
+	}()
+
 	// this delay seems to help with older DOS-based terminals running in DosBox.
 	time.Sleep(1 * time.Second)
 
 	term := session.Terminal
-	// make our hangup handler global
-	hangupChannel = hangup
 
-	// make sure hangup occurs at the end
-	defer func() {
-		hangupChannel <- session
-	}()
+	log.Debugf("%s - Terminal type: %v, capabilities: %d", session.OriginAddress, term.GetTerminalTypes(), term.GetCapabilities())
 
-	// set metrics
-	monitoring.CurrentConnections.Inc()
-	switch session.ConnectionType {
-	case config.TCPRaw:
-		monitoring.CurrentRawConnections.Inc()
-	case config.TCPTelnet:
-		monitoring.CurrentTelnetConnections.Inc()
-	case config.TCPSSH:
-		monitoring.CurrentSSHConnections.Inc()
+	// get a node number
+	err := register(session)
+	if err != nil {
+		log.Infof("%s - Session refused: %s", session.OriginAddress, err.Error())
+		term.SetColor(ansiterm.Red, true)
+		term.Println("\nAll nodes are in use. Please try again later.")
+		return
 	}
+	defer unregister(session)
+	session.SetLocation("Login")
 
 	// start here
 	term.ClearScreen()
@@ -88,44 +323,246 @@ func Start(session *TerminalSession, hangup chan<- *TerminalSession) {
 
 	term.Printf("%s\n", line)
 
-	// qr test
-	var qrBuffer strings.Builder
-	qrConfig := qrterminal.Config{
-		Level:      qrterminal.M,
-		Writer:     &qrBuffer,
-		HalfBlocks: false,
-		BlackChar:  qrterminal.WHITE,
-		WhiteChar:  qrterminal.BLACK,
-		QuietZone:  1,
+	// users authenticated by ssh don't need to login again
+	if session.User == nil {
+		term.SetColor(ansiterm.White, false)
+		term.Print("\nPlease enter your username, or NEW to create an account: ")
+		// telnet clients can send the name of the user, so we use it as the default.
+		result, err := term.InputDefault(25, ansiterm.InputUpfirst, session.Getenv("USER"))
+		if err != nil {
+			return
+		}
+
+		if strings.EqualFold(result, "new") {
+			newUser := registerUser(session)
+			if newUser == nil {
+				return
+			}
+			session.setUser(newUser)
+		} else {
+			if !checkLogin(session, result) {
+				return
+			}
+			term.Print("\nPlease enter your password: ")
+			pwResult, err := term.Input(passwordFieldSize, ansiterm.InputPassword)
+			if err != nil {
+				return
+			}
+
+			loginUser := user.Authenticate(result, pwResult)
+			if loginUser == nil {
+				log.Infof("%s - Wrong password for user %s", session.OriginAddress, result)
+				guard.RecordFailure(result, session.OriginAddress)
+				term.Println("Password incorrect. Disconnecting...")
+				return
+			}
+			if loginUser.TOTPEnabled() && !askSecondFactor(session, loginUser) {
+				term.Println("Disconnecting...")
+				return
+			}
+			guard.RecordSuccess(loginUser.Username)
+			session.setUser(loginUser)
+		}
+	}
+	log.Infof("%s - User %s logged in on node %d", session.OriginAddress, session.Username(), session.Node)
+
+	session.SetLocation("City Square")
+	term.SendTextFile("ansi/citysquare.ans")
+	term.Printf("Welcome %s", session.User.Handle)
+	if !session.User.LastSeen.IsZero() {
+		term.Printf("\nYour last visit was on %s.", session.User.LastSeen.Local().Format("January 2 at 15:04"))
 	}
-	// Taken from google example: https://github.com/google/google-authenticator/wiki/Key-Uri-Format
-	qrterminal.GenerateWithConfig("otpauth://totp/Example:alice@google.com?secret=JBSWY3DPEHPK3PXP&issuer=Example", qrConfig)
-	// term.Print(qrBuffer.String())
+	sendCharacterInfo(session, session.Handle())
 
-	adminUser := user.User{}
-	adminUser.Username = "test user"
-	adminUser.SetPassword("mytestpw")
+	for {
+		term.Print("\n\n")
+		term.DisplayMenuItem('W', "Who's online\n")
+		term.DisplayMenuItem('P', "Page a player\n")
+		term.DisplayMenuItem('I', "The Inn\n")
+		term.DisplayMenuItem('D', "Dark Cloak Tavern\n")
+		term.DisplayMenuItem('K', "Add ssh public key\n")
+		term.DisplayMenuItem('T', "Two-factor authentication\n")
+		term.DisplayMenuItem('Q', "Quit\n")
+		choice, err := term.WaitKeys("WPIDKTQ", true)
+		if err == ansiterm.ErrInterrupted {
+			continue
+		}
+		if err != nil {
+			return
+		}
+		switch choice {
+		case 'W':
+			showWhosOnline(session)
+		case 'P':
+			pagePlayer(session)
+		case 'I':
+			chat(session, "The Inn")
+		case 'D':
+			chat(session, "Dark Cloak Tavern")
+		case 'K':
+			addAuthorizedKey(session)
+		case 'T':
+			manageTwoFactor(session)
+		case 'Q':
+			return
+		}
+		session.SetLocation("City Square")
+	}
+}
 
+// shows the players that are connected, like the node list of a BBS.
+func showWhosOnline(session *TerminalSession) {
+	session.SetLocation("Who's Online")
+	term := session.Terminal
+	term.SetColor(ansiterm.White, true)
+	term.Printf("\n%-5s %-25s %-20s %-10s %s\n", "Node", "Player", "Location", "Protocol", "Online")
+	term.SetColor(ansiterm.Green, false)
+	for _, info := range List() {
+		name := info.Handle
+		if name == "" {
+			name = "(logging in)"
+		}
+		online := time.Since(info.ConnectedAt).Truncate(time.Minute)
+		term.Printf("%-5d %-25s %-20s %-10s %s\n", info.Node, name, info.Location, info.Protocol, formatDuration(online))
+	}
+}
+
+// formats a duration in hours and minutes, eg: 1h05m
+func formatDuration(d time.Duration) string {
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	return fmt.Sprintf("%dh%02dm", hours, minutes)
+}
+
+// sends a private message to another player.
+func pagePlayer(session *TerminalSession) {
+	term := session.Terminal
 	term.SetColor(ansiterm.White, false)
-	term.Print("\nPlease enter your username: ")
-	result, err := term.Input(25, ansiterm.InputUpfirst)
-	if err != nil {
+	term.Print("\nPage who? ")
+	name, err := term.Input(user.MaxHandleLength, ansiterm.InputUpfirst)
+	if err != nil || name == "" {
 		return
 	}
+	if FindByHandle(name) == nil {
+		term.SetColor(ansiterm.Red, true)
+		term.Printf("%s is not online.\n", name)
+		return
+	}
+	term.SetColor(ansiterm.White, false)
+	term.Print("Message: ")
+	text, err := term.InputLine(maxMessageLength)
+	if err != nil || strings.TrimSpace(text) == "" {
+		return
+	}
+	err = Page(session, name, text)
+	switch err {
+	case nil:
+		term.SetColor(ansiterm.Green, true)
+		term.Println("Your page has been sent.")
+	case ErrNotOnline:
+		term.SetColor(ansiterm.Red, true)
+		term.Printf("%s is not online anymore.\n", name)
+	default:
+		term.SetColor(ansiterm.Red, true)
+		term.Printf("%s didn't receive your page: %s.\n", name, err.Error())
+	}
+}
 
-	term.Print("\nPlease enter your password: ")
-	pwResult, err := term.Input(32, ansiterm.InputPassword)
+// lets the player talk with the others in a chat room, until they type /q.
+func chat(session *TerminalSession, roomName string) {
+	term := session.Terminal
+	err := JoinRoom(session, roomName)
 	if err != nil {
+		log.Errorln(err.Error())
 		return
 	}
+	defer LeaveRoom(session)
+	session.SetLocation(roomName)
 
-	if adminUser.ValidatePassword(pwResult) {
-		term.SendTextFile("ansi/citysquare.ans")
-		term.Printf("Welcome %s", result)
+	previousTimeout := term.KeyTimeout
+	term.KeyTimeout = chatKeyTimeout
+	defer func() {
+		term.KeyTimeout = previousTimeout
+	}()
 
-	} else {
-		term.Println("Password incorrect. Disconnecting...")
+	term.SetColor(ansiterm.White, true)
+	term.Printf("\nYou enter %s. ", roomName)
+	showRoomMembers(session, roomName)
+	term.SetColor(ansiterm.Green, false)
+	term.Println("Type /w to see who's here, /q to leave.")
+	for {
+		term.SetColor(ansiterm.White, false)
+		term.Print("> ")
+		line, err := term.InputLine(maxMessageLength)
+		if err != nil {
+			return
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "":
+		case "/q":
+			return
+		case "/w":
+			showRoomMembers(session, roomName)
+		default:
+			err = Say(session, line)
+			if err != nil {
+				log.Errorln(err.Error())
+			}
+		}
 	}
+}
 
-	return
+func showRoomMembers(session *TerminalSession, roomName string) {
+	term := session.Terminal
+	term.SetColor(ansiterm.Cyan, false)
+	term.Printf("Here: %s\n", strings.Join(RoomMembers(roomName), ", "))
+}
+
+// tells the player to come back later if there were too many failed logins for the account or from their address
+func checkLogin(session *TerminalSession, username string) bool {
+	wait := guard.CheckLogin(username, session.OriginAddress)
+	if wait <= 0 {
+		return true
+	}
+	term := session.Terminal
+	term.SetColor(ansiterm.Red, true)
+	term.Printf("\nToo many failed logins. Please try again in %s.\n", wait.Round(time.Second))
+	return false
+}
+
+// lets the user register a public key, so they can login over ssh without password.
+func addAuthorizedKey(session *TerminalSession) {
+	term := session.Terminal
+	term.SetColor(ansiterm.White, false)
+	term.Println("\nPaste your public key (eg: the contents of ~/.ssh/id_ed25519.pub):")
+	line, err := term.InputLine(maxAuthorizedKeyLength)
+	if err != nil {
+		return
+	}
+	err = session.User.AddAuthorizedKey(line)
+	if err == user.ErrInvalidKey {
+		term.SetColor(ansiterm.Red, true)
+		term.Println("This is not a valid public key.")
+		return
+	}
+	if err != nil {
+		log.Errorln(err.Error())
+		term.SetColor(ansiterm.Red, true)
+		term.Println("Your key couldn't be saved. Please try again later.")
+		return
+	}
+	term.SetColor(ansiterm.Green, true)
+	term.Println("Your key has been added.")
+}
+
+// push character info to graphical clients
+func sendCharacterInfo(session *TerminalSession, name string) {
+	err := session.OutOfBand.SendGMCP("Char.Name", map[string]string{"name": name})
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+	err = session.OutOfBand.SendMSDP("CHARACTER_NAME", name)
+	if err != nil {
+		log.Errorln(err.Error())
+	}
 }
@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package session
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeroenjacobs79/tobw/internal/ansiterm"
+	"github.com/jeroenjacobs79/tobw/internal/config"
+	"github.com/jeroenjacobs79/tobw/internal/telnet"
+)
+
+const (
+	// telnet Interrupt Process and Break
+	telnetIP    byte = 244
+	telnetBreak byte = 243
+)
+
+// creates a session on a telnet connection, the way the telnet listener does. The input is sent by the client.
+func newTelnetSession(t *testing.T, input []byte) (*TerminalSession, func()) {
+	server, client := net.Pipe()
+	// the client ignores everything we send, including the negotiation
+	go func() {
+		_, _ = io.Copy(ioutil.Discard, client)
+	}()
+	telnetConn := telnet.NewConnection(server)
+	term := ansiterm.CreateAnsiTerminal(telnetConn)
+	term.KeyTimeout = 5 * time.Second
+	s := CreateSession(term, config.TCPTelnet, "test")
+	telnetConn.InstallInterruptHandler(s.Interrupt)
+	go func() {
+		_, err := client.Write(input)
+		if err != nil {
+			t.Log(err.Error())
+		}
+	}()
+	return s, func() {
+		_ = term.Close()
+		_ = client.Close()
+	}
+}
+
+func TestTelnetInterrupt(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []byte
+		line    bool
+		want    string
+		wantErr error
+	}{
+		{"input", []byte("abc\r"), false, "abc", nil},
+		{"interrupt process aborts input", []byte{'a', 'b', 'c', telnet.IAC, telnetIP}, false, "", ansiterm.ErrInterrupted},
+		{"break aborts input", []byte{'a', telnet.IAC, telnetBreak}, false, "", ansiterm.ErrInterrupted},
+		{"line", []byte("hello\r"), true, "hello", nil},
+		{"interrupt process aborts line", []byte{'h', 'i', telnet.IAC, telnetIP}, true, "", ansiterm.ErrInterrupted},
+	}
+	for _, test := range tests {
+		s, closeSession := newTelnetSession(t, test.input)
+		var result string
+		var err error
+		if test.line {
+			result, err = s.Terminal.InputLine(80)
+		} else {
+			result, err = s.Terminal.Input(25, ansiterm.InputAll)
+		}
+		closeSession()
+		if result != test.want || err != test.wantErr {
+			t.Errorf("%s: got %q (error %v), want %q (error %v)", test.name, result, err, test.want, test.wantErr)
+		}
+	}
+}
+
+// A client that stopped reading must not keep a hangup from another goroutine (kick, shutdown) waiting, and the
+// session that is stuck writing to it has to end.
+func TestHangupWhileWriting(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	term := ansiterm.CreateAnsiTerminal(server)
+	s := CreateSession(term, config.TCPRaw, "test")
+	written := make(chan error)
+	go func() {
+		term.Print(strings.Repeat("The client never reads this. ", 1000))
+		written <- term.Flush()
+	}()
+
+	hungUp := make(chan struct{})
+	go func() {
+		s.Hangup()
+		s.Hangup()
+		close(hungUp)
+	}()
+	select {
+	case <-hungUp:
+	case <-time.After(time.Second):
+		t.Fatal("hangup blocked")
+	}
+	select {
+	case err := <-written:
+		if err == nil {
+			t.Error("write to a hung up connection succeeded")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("write still blocked after hangup")
+	}
+}
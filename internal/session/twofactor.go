@@ -0,0 +1,264 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package session
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jeroenjacobs79/tobw/internal/ansiterm"
+	"github.com/jeroenjacobs79/tobw/internal/guard"
+	"github.com/jeroenjacobs79/tobw/internal/totp"
+	"github.com/jeroenjacobs79/tobw/internal/user"
+	"github.com/mdp/qrterminal"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// shown in the authenticator app. Short, so the QR code fits on a small screen.
+	totpIssuer = "TOBW"
+	// lines we need below the QR code, for the secret and the prompt
+	qrReservedRows = 2
+	// long enough for a TOTP code and a recovery code
+	secondFactorFieldSize = 11
+	// how long we wait for the player while they set up their authenticator app
+	enrollKeyTimeout = 10 * time.Minute
+)
+
+// half blocks in CP437, for terminals that get CP437 converted to UTF-8
+var halfBlocksToCP437 = strings.NewReplacer(qrterminal.WHITE_WHITE, "\xdb", qrterminal.WHITE_BLACK, "\xdf", qrterminal.BLACK_WHITE, "\xdc")
+
+// showQRCode draws a QR code at the top of the screen. Big screens get full blocks drawn with background colors,
+// which work on every ANSI terminal. Smaller screens get half blocks, which need a terminal that can show UTF-8.
+// Returns false if the code doesn't fit.
+func showQRCode(term *ansiterm.AnsiTerminal, text string) bool {
+	var halfBlocks strings.Builder
+	qrterminal.GenerateWithConfig(text, qrterminal.Config{
+		Level:          qrterminal.L,
+		Writer:         &halfBlocks,
+		HalfBlocks:     true,
+		BlackChar:      qrterminal.BLACK_BLACK,
+		WhiteBlackChar: qrterminal.WHITE_BLACK,
+		WhiteChar:      qrterminal.WHITE_WHITE,
+		BlackWhiteChar: qrterminal.BLACK_WHITE,
+		QuietZone:      2,
+	})
+	lines := strings.Split(strings.TrimRight(halfBlocks.String(), "\n"), "\n")
+	// width of the code in modules, including the quiet zone
+	modules := utf8.RuneCountInString(lines[0])
+	cols, rows := term.GetTerminalSize()
+	utf8Terminal := term.Cp437toUtf8() || term.HasCapability(ansiterm.CapUTF8)
+
+	switch {
+	case cols >= modules*2 && rows >= modules+qrReservedRows:
+		var fullBlocks strings.Builder
+		qrterminal.GenerateWithConfig(text, qrterminal.Config{
+			Level:     qrterminal.L,
+			Writer:    &fullBlocks,
+			BlackChar: qrterminal.BLACK,
+			WhiteChar: qrterminal.WHITE,
+			QuietZone: 2,
+		})
+		term.ClearScreen()
+		term.GotoXY(1, 1)
+		term.Print(fullBlocks.String())
+	case utf8Terminal && cols >= modules && rows >= len(lines)+qrReservedRows:
+		code := halfBlocks.String()
+		if term.Cp437toUtf8() {
+			code = halfBlocksToCP437.Replace(code)
+		}
+		term.ClearScreen()
+		term.GotoXY(1, 1)
+		term.SetFullColor(ansiterm.White, ansiterm.Black, true)
+		term.Print(code)
+	default:
+		return false
+	}
+	term.Print("\x1B[0m")
+	return true
+}
+
+// formats a secret in groups of 4 characters, so it's easier to type
+func formatSecret(secret string) string {
+	var groups []string
+	for len(secret) > 4 {
+		groups = append(groups, secret[:4])
+		secret = secret[4:]
+	}
+	return strings.Join(append(groups, secret), " ")
+}
+
+// askSecondFactor asks for a TOTP or recovery code after the password was accepted.
+func askSecondFactor(session *TerminalSession, loginUser *user.User) bool {
+	term := session.Terminal
+	for attempt := 0; attempt < maxRegistrationAttempts; attempt++ {
+		term.SetColor(ansiterm.White, false)
+		term.Print("\nEnter the code from your authenticator app, or a recovery code: ")
+		code, err := term.Input(secondFactorFieldSize, ansiterm.InputAll)
+		if err != nil || code == "" {
+			return false
+		}
+		left := loginUser.RecoveryCodesLeft()
+		if loginUser.ValidateSecondFactor(code) {
+			if loginUser.RecoveryCodesLeft() < left {
+				log.Infof("%s - User %s used a recovery code, %d left", session.OriginAddress, loginUser.Username, loginUser.RecoveryCodesLeft())
+				term.SetColor(ansiterm.Yellow, true)
+				term.Printf("You have %d recovery codes left.\n", loginUser.RecoveryCodesLeft())
+			}
+			return true
+		}
+		log.Infof("%s - Invalid two-factor code for user %s", session.OriginAddress, loginUser.Username)
+		guard.RecordFailure(loginUser.Username, session.OriginAddress)
+		term.SetColor(ansiterm.Red, true)
+		term.Println("This code is not correct.")
+		if !checkLogin(session, loginUser.Username) {
+			return false
+		}
+	}
+	return false
+}
+
+// lets the player turn two-factor authentication on or off.
+func manageTwoFactor(session *TerminalSession) {
+	session.SetLocation("Two-factor authentication")
+	term := session.Terminal
+	// the user can have changed in another session (eg: used a recovery code)
+	current := user.Find(session.Username())
+	if current == nil {
+		term.SetColor(ansiterm.Red, true)
+		term.Println("\nYour account couldn't be loaded. Please try again later.")
+		return
+	}
+	session.setUser(current)
+	if !current.TOTPEnabled() {
+		enrollTOTP(session)
+		return
+	}
+	term.SetColor(ansiterm.White, true)
+	term.Printf("\nTwo-factor authentication is enabled. You have %d recovery codes left.\n\n", current.RecoveryCodesLeft())
+	term.DisplayMenuItem('R', "New recovery codes\n")
+	term.DisplayMenuItem('D', "Disable two-factor authentication\n")
+	term.DisplayMenuItem('Q', "Back\n")
+	choice, err := term.WaitKeys("RDQ", true)
+	if err != nil || choice == 'Q' {
+		return
+	}
+	// make sure it's the player, and not somebody at an unlocked terminal
+	term.SetColor(ansiterm.White, false)
+	term.Print("\nEnter the code from your authenticator app: ")
+	code, err := term.Input(totp.Digits, ansiterm.InputDigit)
+	if err != nil || !current.ValidateTOTP(code) {
+		term.SetColor(ansiterm.Red, true)
+		term.Println("This code is not correct.")
+		return
+	}
+	switch choice {
+	case 'R':
+		codes, err := current.NewRecoveryCodes()
+		if err != nil {
+			log.Errorln(err.Error())
+			term.SetColor(ansiterm.Red, true)
+			term.Println("Your recovery codes couldn't be changed. Please try again later.")
+			return
+		}
+		showRecoveryCodes(term, codes)
+	case 'D':
+		err = current.DisableTOTP()
+		if err != nil {
+			log.Errorln(err.Error())
+			term.SetColor(ansiterm.Red, true)
+			term.Println("Two-factor authentication couldn't be disabled. Please try again later.")
+			return
+		}
+		log.Infof("%s - User %s disabled two-factor authentication", session.OriginAddress, session.Username())
+		term.SetColor(ansiterm.Green, true)
+		term.Println("Two-factor authentication is disabled.")
+	}
+}
+
+// shows the QR code of a new secret, and turns on two-factor authentication when the player enters a valid code.
+func enrollTOTP(session *TerminalSession) {
+	term := session.Terminal
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		log.Errorln(err.Error())
+		return
+	}
+	// installing an authenticator app and scanning the code takes a while
+	previousTimeout := term.KeyTimeout
+	term.KeyTimeout = enrollKeyTimeout
+	defer func() {
+		term.KeyTimeout = previousTimeout
+	}()
+
+	term.SetColor(ansiterm.White, true)
+	term.Println("\nWith two-factor authentication, you need a code from an authenticator app on your phone to login.")
+	term.SetColor(ansiterm.Green, false)
+	term.Println("Press any key to show the code for your app, and scan it.")
+	_, err = term.WaitKey(false)
+	if err != nil {
+		return
+	}
+	shown := showQRCode(term, totp.KeyURI(totpIssuer, session.Username(), secret))
+	term.SetColor(ansiterm.White, false)
+	if shown {
+		term.Printf("Or enter this key in your app: %s\n", formatSecret(secret))
+	} else {
+		term.Printf("\nEnter this key in your app: %s\n", formatSecret(secret))
+	}
+
+	// the player has to prove the app works, or they would lock themselves out
+	for attempt := 0; attempt < maxRegistrationAttempts; attempt++ {
+		term.SetColor(ansiterm.White, false)
+		term.Print("Enter the code from your app: ")
+		code, err := term.Input(totp.Digits, ansiterm.InputDigit)
+		if err != nil || code == "" {
+			return
+		}
+		codes, err := session.User.EnableTOTP(secret, code)
+		if err == user.ErrInvalidCode {
+			term.SetColor(ansiterm.Red, true)
+			term.Println("This code is not correct.")
+			continue
+		}
+		if err != nil {
+			log.Errorln(err.Error())
+			term.SetColor(ansiterm.Red, true)
+			term.Println("Two-factor authentication couldn't be enabled. Please try again later.")
+			return
+		}
+		log.Infof("%s - User %s enabled two-factor authentication", session.OriginAddress, session.Username())
+		term.SetColor(ansiterm.Green, true)
+		term.Println("\nTwo-factor authentication is enabled.")
+		showRecoveryCodes(term, codes)
+		return
+	}
+}
+
+func showRecoveryCodes(term *ansiterm.AnsiTerminal, codes []string) {
+	term.SetColor(ansiterm.White, false)
+	term.Println("\nWrite down these recovery codes, and keep them somewhere safe. If you lose your phone, you can login")
+	term.Print("with one of them instead of a code from your app. Every code works only once.\n\n")
+	term.SetColor(ansiterm.Yellow, true)
+	for i, code := range codes {
+		term.Printf("  %-14s", code)
+		if i%2 == 1 {
+			term.Print("\n")
+		}
+	}
+	term.Print("\n")
+}
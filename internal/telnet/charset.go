@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package telnet
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CHARSET option (RFC 2066). Used to agree on UTF-8 or CP437 with the client.
+
+const (
+	charsetRequest  byte = 1
+	charsetAccepted byte = 2
+	charsetRejected byte = 3
+)
+
+// character sets we support, in order of preference
+var supportedCharsets = []string{"UTF-8", "CP437", "IBM437"}
+
+// RequestCharset offers CHARSET to the client. When the client agrees, we send our list of character sets.
+func (c *Conn) RequestCharset() {
+	err := c.EnableLocal(OptCharset)
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
+
+// The handler receives the character set that both sides agreed on, in uppercase.
+func (c *Conn) InstallCharsetHandler(handler func(string)) {
+	c.charsetHandler = handler
+}
+
+// Charset returns the negotiated character set, or an empty string if none was negotiated.
+func (c *Conn) Charset() string {
+	return c.charset
+}
+
+func (c *Conn) sendCharsetRequest() {
+	if c.charsetRequested {
+		return
+	}
+	c.charsetRequested = true
+	c.charsetPending = true
+	payload := []byte{charsetRequest}
+	for _, name := range supportedCharsets {
+		payload = append(payload, ';')
+		payload = append(payload, name...)
+	}
+	err := c.sendSubNegotiation(OptCharset, payload)
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
+
+func (c *Conn) charsetReceived(data []byte) {
+	if len(data) == 0 {
+		log.Errorf("%s - Incorrect CHARSET subnegotiation.", c.RemoteAddr())
+		return
+	}
+	switch data[0] {
+	case charsetAccepted:
+		c.charsetPending = false
+		name := string(data[1:])
+		// the client can only pick one of the character sets we offered
+		if !c.charsetRequested || !isSupportedCharset(name) {
+			log.Debugf("%s - Ignoring accepted character set %q, we didn't offer it", c.RemoteAddr(), name)
+			break
+		}
+		c.setCharset(name)
+	case charsetRejected:
+		c.charsetPending = false
+		log.Debugf("%s - Client rejected all our character sets", c.RemoteAddr())
+	case charsetRequest:
+		// the client sends its own list. First byte is the separator.
+		if len(data) < 2 {
+			break
+		}
+		// when both sides send a request at the same time, the request of the server wins.
+		if c.charsetPending {
+			log.Debugf("%s - Ignoring CHARSET request of client, our own request is still pending", c.RemoteAddr())
+			break
+		}
+		separator := string(data[1:2])
+		for _, name := range strings.Split(string(data[2:]), separator) {
+			if isSupportedCharset(name) {
+				err := c.sendSubNegotiation(OptCharset, append([]byte{charsetAccepted}, name...))
+				if err != nil {
+					log.Errorln(err.Error())
+				}
+				c.setCharset(name)
+				return
+			}
+		}
+		err := c.sendSubNegotiation(OptCharset, []byte{charsetRejected})
+		if err != nil {
+			log.Errorln(err.Error())
+		}
+	}
+}
+
+func (c *Conn) setCharset(name string) {
+	c.charset = strings.ToUpper(name)
+	log.Debugf("%s - Character set negotiated: %s", c.RemoteAddr(), c.charset)
+	if c.charsetHandler != nil {
+		c.charsetHandler(c.charset)
+	}
+}
+
+func isSupportedCharset(name string) bool {
+	for _, supported := range supportedCharsets {
+		if strings.EqualFold(name, supported) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package telnet
+
+import "testing"
+
+func TestCharsetAccepted(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested bool
+		accepted  string
+		want      string
+	}{
+		{"one we offered", true, "UTF-8", "UTF-8"},
+		{"one we offered, lowercase", true, "cp437", "CP437"},
+		{"one we didn't offer", true, "ISO-8859-1", ""},
+		{"without our request", false, "UTF-8", ""},
+	}
+	for _, test := range tests {
+		sb := append([]byte{IAC, cmdSB, OptCharset, charsetAccepted}, test.accepted...)
+		c, _ := newTestConn(append(sb, IAC, cmdSE)...)
+		if test.requested {
+			c.sendCharsetRequest()
+		}
+		readAll(c)
+		if c.Charset() != test.want {
+			t.Errorf("%s: character set %q, want %q", test.name, c.Charset(), test.want)
+		}
+		if c.charsetPending {
+			t.Errorf("%s: still waiting for the client", test.name)
+		}
+	}
+}
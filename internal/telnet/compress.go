@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package telnet
+
+import (
+	"compress/zlib"
+	"io"
+
+	"github.com/jeroenjacobs79/tobw/internal/monitoring"
+	log "github.com/sirupsen/logrus"
+)
+
+// MUD Client Compression Protocol.
+// MCCP2: everything we send after IAC SB MCCP2 IAC SE is a zlib stream. See: https://tintin.mudhalla.net/protocols/mccp/
+// MCCP3: everything the client sends after IAC SB MCCP3 IAC SE is a zlib stream.
+
+// counts the bytes that are actually written to the connection, so we know how much compression saved us.
+type countingWriter struct {
+	io.Writer
+	count int64
+}
+
+func (w *countingWriter) Write(data []byte) (n int, err error) {
+	n, err = w.Writer.Write(data)
+	w.count += int64(n)
+	return
+}
+
+// rawReader returns the data that was left over from a previous read first, before reading from the connection.
+// It implements io.ByteReader, so the zlib decompressor doesn't read more data from the connection than it needs.
+type rawReader struct {
+	c *Conn
+}
+
+func (r rawReader) Read(data []byte) (int, error) {
+	if len(r.c.pending) > 0 {
+		n := copy(data, r.c.pending)
+		r.c.pending = r.c.pending[n:]
+		return n, nil
+	}
+	return r.c.in.Read(data)
+}
+
+func (r rawReader) ReadByte() (byte, error) {
+	if len(r.c.pending) > 0 {
+		b := r.c.pending[0]
+		r.c.pending = r.c.pending[1:]
+		return b, nil
+	}
+	return r.c.in.ReadByte()
+}
+
+// RequestCompression offers MCCP2 and MCCP3 to the client. Compression starts when the client agrees.
+func (c *Conn) RequestCompression() {
+	err := c.EnableLocal(OptMCCP2)
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+	err = c.EnableLocal(OptMCCP3)
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
+
+// all writes to the connection should pass here, so they get compressed when MCCP2 is active.
+func (c *Conn) rawWrite(data []byte) (n int, err error) {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	if c.compressor == nil {
+		return c.Conn.Write(data)
+	}
+	n, err = c.compressor.Write(data)
+	if err != nil {
+		return
+	}
+	// flush after every write, otherwise the client doesn't receive anything until the zlib buffer is full.
+	err = c.compressor.Flush()
+	c.uncompressedCount += int64(n)
+	c.updateCompressionMetrics()
+	return
+}
+
+func (c *Conn) startCompression() {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	if c.compressor != nil {
+		return
+	}
+	// this needs to be sent uncompressed, everything after it is compressed.
+	_, err := c.Conn.Write([]byte{IAC, cmdSB, OptMCCP2, IAC, cmdSE})
+	if err != nil {
+		log.Errorln(err.Error())
+		return
+	}
+	c.compressedOut = &countingWriter{Writer: c.Conn}
+	c.compressor = zlib.NewWriter(c.compressedOut)
+	log.Debugf("%s - MCCP2 compression started", c.RemoteAddr())
+}
+
+func (c *Conn) stopCompression() {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	if c.compressor == nil {
+		return
+	}
+	// closing the zlib stream tells the client compression has ended.
+	err := c.compressor.Close()
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+	c.updateCompressionMetrics()
+	c.compressor = nil
+	log.Debugf("%s - MCCP2 compression stopped", c.RemoteAddr())
+}
+
+// Prometheus counters can't decrease, so we only add what we saved on top of what we reported earlier.
+func (c *Conn) updateCompressionMetrics() {
+	saved := c.uncompressedCount - c.compressedOut.count
+	if saved > c.reportedSaved {
+		monitoring.TelnetCompressionBytesSaved.Add(float64(saved - c.reportedSaved))
+		c.reportedSaved = saved
+	}
+}
+
+// returns the reader we should use for incoming data. This is the decompressor once MCCP3 is active.
+func (c *Conn) input() (io.Reader, error) {
+	if c.startDecompress {
+		c.startDecompress = false
+		decompressor, err := zlib.NewReader(rawReader{c: c})
+		if err != nil {
+			return nil, err
+		}
+		c.decompressor = decompressor
+		log.Debugf("%s - MCCP3 decompression started", c.RemoteAddr())
+	}
+	if c.decompressor != nil {
+		return c.decompressor, nil
+	}
+	return rawReader{c: c}, nil
+}
+
+// Close ends the compressed stream properly before closing the connection. It's safe to call this more than once.
+func (c *Conn) Close() (err error) {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.stopCompression()
+		err = c.Conn.Close()
+	})
+	return
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package telnet
+
+import (
+	"bytes"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// NEW-ENVIRON option (RFC 1572). The client sends us its environment variables (USER, LANG, ...).
+
+const (
+	environIs   byte = 0
+	environSend byte = 1
+	environInfo byte = 2
+
+	environVar     byte = 0
+	environValue   byte = 1
+	environEsc     byte = 2
+	environUserVar byte = 3
+)
+
+func (c *Conn) RequestEnvironment() {
+	err := c.EnableRemote(OptNewEnviron)
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
+
+// The handler receives all variables we know so far. It's called again when the client sends updates.
+func (c *Conn) InstallEnvironHandler(handler func(map[string]string)) {
+	c.environHandler = handler
+}
+
+// Environment returns a copy of the variables the client sent us.
+func (c *Conn) Environment() map[string]string {
+	result := make(map[string]string, len(c.environ))
+	for key, value := range c.environ {
+		result[key] = value
+	}
+	return result
+}
+
+func (c *Conn) sendEnvironRequest() {
+	c.environPending = true
+	// an empty VAR and USERVAR list means: send everything
+	err := c.sendSubNegotiation(OptNewEnviron, []byte{environSend, environVar, environUserVar})
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
+
+func (c *Conn) environReceived(data []byte) {
+	if len(data) == 0 || (data[0] != environIs && data[0] != environInfo) {
+		log.Errorf("%s - Incorrect NEW-ENVIRON subnegotiation.", c.RemoteAddr())
+		return
+	}
+	c.environPending = false
+	if c.environ == nil {
+		c.environ = make(map[string]string)
+	}
+
+	var name, value bytes.Buffer
+	var current *bytes.Buffer
+	inVariable := false
+	store := func() {
+		if inVariable && name.Len() > 0 {
+			c.environ[name.String()] = value.String()
+			log.Debugf("%s - Environment variable received: %s=%s", c.RemoteAddr(), name.String(), value.String())
+		}
+		name.Reset()
+		value.Reset()
+	}
+
+	for index := 1; index < len(data); index++ {
+		switch data[index] {
+		case environVar, environUserVar:
+			store()
+			inVariable = true
+			current = &name
+		case environValue:
+			current = &value
+		case environEsc:
+			// next byte is escaped and should be treated as data
+			index++
+			if index < len(data) && current != nil {
+				current.WriteByte(data[index])
+			}
+		default:
+			if current != nil {
+				current.WriteByte(data[index])
+			}
+		}
+	}
+	store()
+
+	if c.environHandler != nil {
+		c.environHandler(c.Environment())
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package telnet
+
+import (
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Keepalive detects half-open connections. When nothing was received from the client during the interval, we send
+// DO TIMING-MARK (RFC 860). Every client has to answer this with WILL or WONT TIMING-MARK. If we don't receive
+// anything within the timeout, the connection is considered dead.
+//
+// Some clients don't handle TIMING-MARK well. For those, a NOP can be sent instead. A NOP doesn't get an answer, so
+// then we can only detect dead connections when the write fails.
+
+func (c *Conn) StartKeepalive(interval time.Duration, timeout time.Duration, useNop bool, onTimeout func()) {
+	c.touch()
+	go c.keepalive(interval, timeout, useNop, onTimeout)
+}
+
+func (c *Conn) keepalive(interval time.Duration, timeout time.Duration, useNop bool, onTimeout func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+		if time.Since(c.lastActivity()) < interval {
+			// we received something recently, no need to check
+			continue
+		}
+
+		probeSent := time.Now()
+		var err error
+		if useNop {
+			log.Tracef("%s - Sending keepalive NOP", c.RemoteAddr())
+			err = c.SendCommand(cmdNop)
+		} else {
+			log.Tracef("%s - Sending keepalive DO TIMING-MARK", c.RemoteAddr())
+			err = c.SendDo(OptTimingMark)
+		}
+		if err != nil {
+			log.Infof("%s - Keepalive failed: %s", c.RemoteAddr(), err.Error())
+			onTimeout()
+			return
+		}
+		if useNop {
+			continue
+		}
+
+		select {
+		case <-c.done:
+			return
+		case <-time.After(timeout):
+		}
+		if c.lastActivity().Before(probeSent) {
+			log.Infof("%s - No keepalive response received within %s", c.RemoteAddr(), timeout)
+			onTimeout()
+			return
+		}
+	}
+}
+
+// touch stores the time we last received data from the client.
+func (c *Conn) touch() {
+	atomic.StoreInt64(&c.activity, time.Now().UnixNano())
+}
+
+func (c *Conn) lastActivity() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&c.activity))
+}
+
+// TIMING-MARK isn't a real option, every DO or WILL is a new request that needs an answer.
+// That's why it's handled outside of the Q Method state machine.
+func (c *Conn) timingMarkHandler(command byte) {
+	var err error
+	switch command {
+	case cmdWill, cmdWont:
+		// Read already stored the activity, like it does for all data we receive
+		log.Tracef("%s - Keepalive response received", c.RemoteAddr())
+	case cmdDo:
+		// all data before this point has been processed, so we can answer immediately
+		err = c.SendWill(OptTimingMark)
+	}
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
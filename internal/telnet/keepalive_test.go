@@ -0,0 +1,45 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package telnet
+
+import (
+	"bytes"
+	"testing"
+)
+
+// The answer to our DO TIMING-MARK is only a few bytes, but it has to count as activity.
+func TestTimingMarkResponse(t *testing.T) {
+	for _, command := range []byte{cmdWill, cmdWont} {
+		c, fake := newTestConn(IAC, command, OptTimingMark)
+		readAll(c)
+		if c.activity == 0 {
+			t.Errorf("command %d: no activity recorded", command)
+		}
+		if sent := fake.written(); len(sent) != 0 {
+			t.Errorf("command %d: sent %v, want nothing", command, sent)
+		}
+	}
+}
+
+func TestTimingMarkRequest(t *testing.T) {
+	c, fake := newTestConn(IAC, cmdDo, OptTimingMark, IAC, cmdDo, OptTimingMark)
+	readAll(c)
+	// every request is answered, it isn't an option that stays enabled
+	want := []byte{IAC, cmdWill, OptTimingMark, IAC, cmdWill, OptTimingMark}
+	if sent := fake.written(); !bytes.Equal(sent, want) {
+		t.Errorf("sent %v, want %v", sent, want)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package telnet
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// LINEMODE option (RFC 1184). We do all line editing and echo'ing ourselves, so when a client insists on
+// linemode, we ask it to turn off local editing. Whatever the client sends us is still handled correctly,
+// since the terminal reads input one character at a time.
+
+const (
+	linemodeMode        byte = 1
+	linemodeForwardMask byte = 2
+	linemodeSLC         byte = 3
+
+	linemodeModeEdit    byte = 1
+	linemodeModeTrapSig byte = 2
+	linemodeModeAck     byte = 4
+
+	// we want the client to send every character immediately, without any local editing.
+	linemodeWantedMode byte = 0
+)
+
+func (c *Conn) sendLinemodeMode(mode byte) {
+	err := c.sendSubNegotiation(OptLinemode, []byte{linemodeMode, mode})
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
+
+func (c *Conn) linemodeReceived(data []byte) {
+	if len(data) == 0 {
+		log.Errorf("%s - Incorrect LINEMODE subnegotiation.", c.RemoteAddr())
+		return
+	}
+	switch data[0] {
+	case linemodeMode:
+		if len(data) < 2 {
+			log.Errorf("%s - Incorrect LINEMODE MODE subnegotiation.", c.RemoteAddr())
+			return
+		}
+		mode := data[1]
+		if mode&linemodeModeAck != 0 {
+			// client acknowledged a mode
+			log.Debugf("%s - LINEMODE mode acknowledged: %d", c.RemoteAddr(), mode&^linemodeModeAck)
+			return
+		}
+		if mode&(linemodeModeEdit|linemodeModeTrapSig) == linemodeWantedMode {
+			// client proposes the mode we want, acknowledge it
+			c.sendLinemodeMode(mode | linemodeModeAck)
+		} else {
+			// tell the client which mode we want instead
+			c.sendLinemodeMode(linemodeWantedMode)
+		}
+	case linemodeSLC:
+		// we don't use the special characters of the client, the terminal handles control characters itself.
+		log.Debugf("%s - Ignoring LINEMODE SLC subnegotiation", c.RemoteAddr())
+	case cmdDo, cmdDont, cmdWill, cmdWont:
+		// forward mask negotiation, which we don't support
+		if len(data) > 1 && data[1] == linemodeForwardMask && data[0] == cmdDo {
+			err := c.sendSubNegotiation(OptLinemode, []byte{cmdWont, linemodeForwardMask})
+			if err != nil {
+				log.Errorln(err.Error())
+			}
+		}
+	default:
+		log.Debugf("%s - Unknown LINEMODE subnegotiation received (%d). Ignoring.", c.RemoteAddr(), data[0])
+	}
+}
@@ -0,0 +1,266 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package telnet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Out-of-band data for graphical MUD clients like Mudlet.
+// GMCP: https://tintin.mudhalla.net/protocols/gmcp/
+// MSDP: https://tintin.mudhalla.net/protocols/msdp/
+
+const (
+	msdpVar        byte = 1
+	msdpVal        byte = 2
+	msdpTableOpen  byte = 3
+	msdpTableClose byte = 4
+	msdpArrayOpen  byte = 5
+	msdpArrayClose byte = 6
+)
+
+// RequestOutOfBand offers GMCP and MSDP to the client.
+func (c *Conn) RequestOutOfBand() {
+	err := c.EnableLocal(OptGMCP)
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+	err = c.EnableLocal(OptMSDP)
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
+
+// The handler receives the package name (eg: "Core.Hello") and the raw JSON data, which can be empty.
+func (c *Conn) InstallGMCPHandler(handler func(string, []byte)) {
+	c.gmcpHandler = handler
+}
+
+// The handler receives the variable name and its value. The value is a string, []interface{} or map[string]interface{}.
+func (c *Conn) InstallMSDPHandler(handler func(string, interface{})) {
+	c.msdpHandler = handler
+}
+
+// SendGMCP sends a GMCP message. The payload is encoded as JSON, a nil payload sends the package name only.
+// Nothing is sent if the client didn't enable GMCP.
+func (c *Conn) SendGMCP(pkg string, payload interface{}) error {
+	if !c.IsEnabledLocal(OptGMCP) {
+		return nil
+	}
+	var buffer bytes.Buffer
+	buffer.WriteString(pkg)
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		buffer.WriteByte(' ')
+		buffer.Write(data)
+	}
+	return c.sendSubNegotiation(OptGMCP, buffer.Bytes())
+}
+
+// SendMSDP sends a MSDP variable. The value can be a string, number, boolean, slice or map with string keys.
+// Nothing is sent if the client didn't enable MSDP.
+func (c *Conn) SendMSDP(variable string, value interface{}) error {
+	if !c.IsEnabledLocal(OptMSDP) {
+		return nil
+	}
+	var buffer bytes.Buffer
+	buffer.WriteByte(msdpVar)
+	buffer.WriteString(variable)
+	buffer.WriteByte(msdpVal)
+	err := encodeMSDPValue(&buffer, value)
+	if err != nil {
+		return err
+	}
+	return c.sendSubNegotiation(OptMSDP, buffer.Bytes())
+}
+
+func encodeMSDPValue(buffer *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		// empty value
+	case string:
+		buffer.WriteString(v)
+	case []string:
+		buffer.WriteByte(msdpArrayOpen)
+		for _, element := range v {
+			buffer.WriteByte(msdpVal)
+			buffer.WriteString(element)
+		}
+		buffer.WriteByte(msdpArrayClose)
+	case []interface{}:
+		buffer.WriteByte(msdpArrayOpen)
+		for _, element := range v {
+			buffer.WriteByte(msdpVal)
+			err := encodeMSDPValue(buffer, element)
+			if err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(msdpArrayClose)
+	case map[string]string:
+		generic := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			generic[key] = element
+		}
+		return encodeMSDPValue(buffer, generic)
+	case map[string]interface{}:
+		// sort keys, so we always send the same output for the same table
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buffer.WriteByte(msdpTableOpen)
+		for _, key := range keys {
+			buffer.WriteByte(msdpVar)
+			buffer.WriteString(key)
+			buffer.WriteByte(msdpVal)
+			err := encodeMSDPValue(buffer, v[key])
+			if err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(msdpTableClose)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
+		fmt.Fprint(buffer, v)
+	default:
+		return fmt.Errorf("Unsupported MSDP value type: %T", value)
+	}
+	return nil
+}
+
+// decodes a single value. Returns the value and the remaining data.
+func decodeMSDPValue(data []byte) (value interface{}, rest []byte) {
+	if len(data) == 0 {
+		return "", data
+	}
+	switch data[0] {
+	case msdpTableOpen:
+		table := make(map[string]interface{})
+		data = data[1:]
+		for len(data) > 0 && data[0] != msdpTableClose {
+			var name string
+			var element interface{}
+			name, element, data = decodeMSDPVariable(data)
+			table[name] = element
+		}
+		if len(data) > 0 {
+			data = data[1:]
+		}
+		return table, data
+	case msdpArrayOpen:
+		array := []interface{}{}
+		data = data[1:]
+		for len(data) > 0 && data[0] != msdpArrayClose {
+			if data[0] != msdpVal {
+				// malformed, skip byte
+				data = data[1:]
+				continue
+			}
+			var element interface{}
+			element, data = decodeMSDPValue(data[1:])
+			array = append(array, element)
+		}
+		if len(data) > 0 {
+			data = data[1:]
+		}
+		return array, data
+	default:
+		end := 0
+		for end < len(data) && data[end] > msdpArrayClose {
+			end++
+		}
+		return string(data[:end]), data[end:]
+	}
+}
+
+// decodes VAR name VAL value. A variable can have multiple values, these are returned as an array.
+func decodeMSDPVariable(data []byte) (name string, value interface{}, rest []byte) {
+	if len(data) == 0 || data[0] != msdpVar {
+		// malformed, drop everything
+		return "", "", nil
+	}
+	end := 1
+	for end < len(data) && data[end] > msdpArrayClose {
+		end++
+	}
+	name = string(data[1:end])
+	data = data[end:]
+	var values []interface{}
+	for len(data) > 0 && data[0] == msdpVal {
+		var element interface{}
+		element, data = decodeMSDPValue(data[1:])
+		values = append(values, element)
+	}
+	switch len(values) {
+	case 0:
+		value = ""
+	case 1:
+		value = values[0]
+	default:
+		value = values
+	}
+	return name, value, data
+}
+
+func (c *Conn) gmcpReceived(data []byte) {
+	pkg := data
+	var payload []byte
+	index := bytes.IndexByte(data, ' ')
+	if index != -1 {
+		pkg = data[:index]
+		payload = bytes.TrimSpace(data[index+1:])
+	}
+	log.Debugf("%s - GMCP received: %s %s", c.RemoteAddr(), pkg, payload)
+	if c.gmcpHandler != nil {
+		c.gmcpHandler(string(pkg), payload)
+	}
+}
+
+func (c *Conn) msdpReceived(data []byte) {
+	for len(data) > 0 {
+		var name string
+		var value interface{}
+		name, value, data = decodeMSDPVariable(data)
+		if name == "" {
+			log.Errorf("%s - Malformed MSDP subnegotiation.", c.RemoteAddr())
+			return
+		}
+		log.Debugf("%s - MSDP received: %s = %v", c.RemoteAddr(), name, value)
+		if c.msdpHandler != nil {
+			c.msdpHandler(name, value)
+		}
+	}
+}
+
+// sends IAC SB <option> <payload> IAC SE. IAC bytes in the payload are escaped.
+func (c *Conn) sendSubNegotiation(option byte, payload []byte) error {
+	var buffer bytes.Buffer
+	buffer.Write([]byte{IAC, cmdSB, option})
+	buffer.Write(bytes.Replace(payload, []byte{IAC}, []byte{IAC, IAC}, -1))
+	buffer.Write([]byte{IAC, cmdSE})
+	_, err := c.rawWrite(buffer.Bytes())
+	return err
+}
@@ -0,0 +1,301 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package telnet
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// Option negotiation using the Q Method described in RFC 1143.
+// See: https://tools.ietf.org/html/rfc1143
+//
+// Every option has two sides: "us" (the option is enabled on the server side, negotiated with WILL/WONT)
+// and "him" (the option is enabled on the client side, negotiated with DO/DONT).
+// Each side has a state and a queue bit, which prevents negotiation loops.
+
+type optionState int
+
+const (
+	optNo optionState = iota
+	optWantNo
+	optWantYes
+	optYes
+)
+
+type optionQueue int
+
+const (
+	queueEmpty optionQueue = iota
+	queueOpposite
+)
+
+type optionEntry struct {
+	us    optionState
+	usq   optionQueue
+	him   optionState
+	himq  optionQueue
+	local bool // we are willing to enable this option on our side
+	peer  bool // we allow the client to enable this option on its side
+}
+
+// SupportOption sets whether we agree when the client asks us to enable an option locally (DO),
+// or when the client offers to enable an option on its side (WILL).
+func (c *Conn) SupportOption(option byte, local bool, remote bool) {
+	c.optionLock.Lock()
+	defer c.optionLock.Unlock()
+	c.options[option].local = local
+	c.options[option].peer = remote
+}
+
+// IsEnabledLocal returns true if the option is active on the server side.
+func (c *Conn) IsEnabledLocal(option byte) bool {
+	c.optionLock.Lock()
+	defer c.optionLock.Unlock()
+	return c.options[option].us == optYes
+}
+
+// IsEnabledRemote returns true if the option is active on the client side.
+func (c *Conn) IsEnabledRemote(option byte) bool {
+	c.optionLock.Lock()
+	defer c.optionLock.Unlock()
+	return c.options[option].him == optYes
+}
+
+// EnableLocal asks the client to let us enable an option on our side.
+func (c *Conn) EnableLocal(option byte) (err error) {
+	c.optionLock.Lock()
+	defer c.optionLock.Unlock()
+	o := &c.options[option]
+	// we are always willing to enable the options we ask for ourselves
+	o.local = true
+	switch o.us {
+	case optNo:
+		o.us = optWantYes
+		err = c.SendWill(option)
+	case optYes:
+		log.Tracef("%s - Option %d is already enabled locally", c.RemoteAddr(), option)
+	case optWantNo:
+		if o.usq == queueEmpty {
+			o.usq = queueOpposite
+		}
+	case optWantYes:
+		o.usq = queueEmpty
+	}
+	return
+}
+
+// DisableLocal tells the client we want to disable an option on our side.
+func (c *Conn) DisableLocal(option byte) (err error) {
+	c.optionLock.Lock()
+	defer c.optionLock.Unlock()
+	o := &c.options[option]
+	switch o.us {
+	case optNo:
+		log.Tracef("%s - Option %d is already disabled locally", c.RemoteAddr(), option)
+	case optYes:
+		o.us = optWantNo
+		err = c.SendWont(option)
+	case optWantNo:
+		o.usq = queueEmpty
+	case optWantYes:
+		if o.usq == queueEmpty {
+			o.usq = queueOpposite
+		}
+	}
+	return
+}
+
+// EnableRemote asks the client to enable an option on its side.
+func (c *Conn) EnableRemote(option byte) (err error) {
+	c.optionLock.Lock()
+	defer c.optionLock.Unlock()
+	o := &c.options[option]
+	o.peer = true
+	switch o.him {
+	case optNo:
+		o.him = optWantYes
+		err = c.SendDo(option)
+	case optYes:
+		log.Tracef("%s - Option %d is already enabled remotely", c.RemoteAddr(), option)
+	case optWantNo:
+		if o.himq == queueEmpty {
+			o.himq = queueOpposite
+		}
+	case optWantYes:
+		o.himq = queueEmpty
+	}
+	return
+}
+
+// DisableRemote asks the client to disable an option on its side.
+func (c *Conn) DisableRemote(option byte) (err error) {
+	c.optionLock.Lock()
+	defer c.optionLock.Unlock()
+	o := &c.options[option]
+	switch o.him {
+	case optNo:
+		log.Tracef("%s - Option %d is already disabled remotely", c.RemoteAddr(), option)
+	case optYes:
+		o.him = optWantNo
+		err = c.SendDont(option)
+	case optWantNo:
+		o.himq = queueEmpty
+	case optWantYes:
+		if o.himq == queueEmpty {
+			o.himq = queueOpposite
+		}
+	}
+	return
+}
+
+// The functions below are called by the read loop when WILL/WONT/DO/DONT is received.
+// They return true if the state of the option changed from enabled to disabled, or vice versa. An option we asked to
+// disable is enabled until the client confirms it (WANTNO), so that confirmation is a change too.
+
+func (c *Conn) receiveWill(option byte) (changed bool, err error) {
+	c.optionLock.Lock()
+	defer c.optionLock.Unlock()
+	o := &c.options[option]
+	switch o.him {
+	case optNo:
+		if o.peer {
+			o.him = optYes
+			changed = true
+			err = c.SendDo(option)
+		} else {
+			err = c.SendDont(option)
+		}
+	case optYes:
+		// already enabled, ignore
+	case optWantNo:
+		// the client answered our DONT with WILL. This is an error according to the RFC.
+		if o.himq == queueEmpty {
+			log.Debugf("%s - DONT answered by WILL for option %d", c.RemoteAddr(), option)
+			o.him = optNo
+			changed = true
+		} else {
+			log.Debugf("%s - DONT answered by WILL for option %d", c.RemoteAddr(), option)
+			o.him = optYes
+			o.himq = queueEmpty
+			changed = true
+		}
+	case optWantYes:
+		if o.himq == queueEmpty {
+			o.him = optYes
+			changed = true
+		} else {
+			o.him = optWantNo
+			o.himq = queueEmpty
+			err = c.SendDont(option)
+		}
+	}
+	return
+}
+
+func (c *Conn) receiveWont(option byte) (changed bool, err error) {
+	c.optionLock.Lock()
+	defer c.optionLock.Unlock()
+	o := &c.options[option]
+	switch o.him {
+	case optNo:
+		// already disabled, ignore
+	case optYes:
+		o.him = optNo
+		changed = true
+		err = c.SendDont(option)
+	case optWantNo:
+		changed = true
+		if o.himq == queueEmpty {
+			o.him = optNo
+		} else {
+			o.him = optWantYes
+			o.himq = queueEmpty
+			err = c.SendDo(option)
+		}
+	case optWantYes:
+		o.him = optNo
+		o.himq = queueEmpty
+	}
+	return
+}
+
+func (c *Conn) receiveDo(option byte) (changed bool, err error) {
+	c.optionLock.Lock()
+	defer c.optionLock.Unlock()
+	o := &c.options[option]
+	switch o.us {
+	case optNo:
+		if o.local {
+			o.us = optYes
+			changed = true
+			err = c.SendWill(option)
+		} else {
+			err = c.SendWont(option)
+		}
+	case optYes:
+		// already enabled, ignore
+	case optWantNo:
+		// the client answered our WONT with DO. This is an error according to the RFC.
+		if o.usq == queueEmpty {
+			log.Debugf("%s - WONT answered by DO for option %d", c.RemoteAddr(), option)
+			o.us = optNo
+			changed = true
+		} else {
+			log.Debugf("%s - WONT answered by DO for option %d", c.RemoteAddr(), option)
+			o.us = optYes
+			o.usq = queueEmpty
+			changed = true
+		}
+	case optWantYes:
+		if o.usq == queueEmpty {
+			o.us = optYes
+			changed = true
+		} else {
+			o.us = optWantNo
+			o.usq = queueEmpty
+			err = c.SendWont(option)
+		}
+	}
+	return
+}
+
+func (c *Conn) receiveDont(option byte) (changed bool, err error) {
+	c.optionLock.Lock()
+	defer c.optionLock.Unlock()
+	o := &c.options[option]
+	switch o.us {
+	case optNo:
+		// already disabled, ignore
+	case optYes:
+		o.us = optNo
+		changed = true
+		err = c.SendWont(option)
+	case optWantNo:
+		changed = true
+		if o.usq == queueEmpty {
+			o.us = optNo
+		} else {
+			o.us = optWantYes
+			o.usq = queueEmpty
+			err = c.SendWill(option)
+		}
+	case optWantYes:
+		o.us = optNo
+		o.usq = queueEmpty
+	}
+	return
+}
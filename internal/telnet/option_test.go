@@ -0,0 +1,190 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package telnet
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Option negotiation is tested the way a client sees it: what we send in reply to what the client sends.
+
+func TestNegotiationReplies(t *testing.T) {
+	tests := []struct {
+		name string
+		// options we support, and requests we make before the client sends anything
+		setup    func(c *Conn)
+		received []byte
+		reply    []byte
+		// state afterwards
+		local  bool
+		remote bool
+	}{
+		{
+			name:     "client offers a supported option",
+			setup:    func(c *Conn) { c.SupportOption(OptNAWS, false, true) },
+			received: []byte{IAC, cmdWill, OptNAWS},
+			reply:    []byte{IAC, cmdDo, OptNAWS},
+			remote:   true,
+		},
+		{
+			name:     "client offers an unsupported option",
+			setup:    func(c *Conn) {},
+			received: []byte{IAC, cmdWill, OptNAWS},
+			reply:    []byte{IAC, cmdDont, OptNAWS},
+		},
+		{
+			name:     "client offers an option twice",
+			setup:    func(c *Conn) { c.SupportOption(OptNAWS, false, true) },
+			received: []byte{IAC, cmdWill, OptNAWS, IAC, cmdWill, OptNAWS},
+			reply:    []byte{IAC, cmdDo, OptNAWS},
+			remote:   true,
+		},
+		{
+			name:     "client accepts our DO",
+			setup:    func(c *Conn) { c.EnableRemote(OptNAWS) },
+			received: []byte{IAC, cmdWill, OptNAWS},
+			remote:   true,
+		},
+		{
+			name:     "client refuses our DO",
+			setup:    func(c *Conn) { c.EnableRemote(OptNAWS) },
+			received: []byte{IAC, cmdWont, OptNAWS},
+		},
+		{
+			name:     "client disables an option",
+			setup:    func(c *Conn) { c.SupportOption(OptNAWS, false, true) },
+			received: []byte{IAC, cmdWill, OptNAWS, IAC, cmdWont, OptNAWS},
+			reply:    []byte{IAC, cmdDo, OptNAWS, IAC, cmdDont, OptNAWS},
+		},
+		{
+			name:     "client disables an option that is disabled",
+			setup:    func(c *Conn) {},
+			received: []byte{IAC, cmdWont, OptNAWS},
+		},
+		{
+			name:     "client asks for a supported option",
+			setup:    func(c *Conn) { c.SupportOption(OptEcho, true, false) },
+			received: []byte{IAC, cmdDo, OptEcho},
+			reply:    []byte{IAC, cmdWill, OptEcho},
+			local:    true,
+		},
+		{
+			name:     "client asks for an unsupported option",
+			setup:    func(c *Conn) {},
+			received: []byte{IAC, cmdDo, OptEcho},
+			reply:    []byte{IAC, cmdWont, OptEcho},
+		},
+		{
+			name:     "client accepts our WILL",
+			setup:    func(c *Conn) { c.EnableLocal(OptEcho) },
+			received: []byte{IAC, cmdDo, OptEcho},
+			local:    true,
+		},
+		{
+			name:     "client refuses our WILL",
+			setup:    func(c *Conn) { c.EnableLocal(OptEcho) },
+			received: []byte{IAC, cmdDont, OptEcho},
+		},
+		{
+			name: "we disable an option",
+			setup: func(c *Conn) {
+				c.EnableLocal(OptEcho)
+				c.receiveDo(OptEcho)
+				c.DisableLocal(OptEcho)
+			},
+			received: []byte{IAC, cmdDont, OptEcho},
+		},
+		{
+			name: "we change our mind before the client answers",
+			setup: func(c *Conn) {
+				c.EnableRemote(OptNAWS)
+				c.DisableRemote(OptNAWS)
+			},
+			received: []byte{IAC, cmdWill, OptNAWS, IAC, cmdWont, OptNAWS},
+			reply:    []byte{IAC, cmdDont, OptNAWS},
+		},
+		{
+			name: "we change our mind twice before the client answers",
+			setup: func(c *Conn) {
+				c.EnableRemote(OptNAWS)
+				c.DisableRemote(OptNAWS)
+				c.EnableRemote(OptNAWS)
+			},
+			received: []byte{IAC, cmdWill, OptNAWS},
+			remote:   true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, fake := newTestConn(test.received...)
+			test.setup(c)
+			fake.written()
+			readAll(c)
+			if sent := fake.written(); !bytes.Equal(sent, test.reply) {
+				t.Errorf("sent %v, want %v", sent, test.reply)
+			}
+			if c.IsEnabledLocal(OptEcho) != test.local || c.IsEnabledRemote(OptNAWS) != test.remote {
+				t.Errorf("enabled local=%t remote=%t, want local=%t remote=%t",
+					c.IsEnabledLocal(OptEcho), c.IsEnabledRemote(OptNAWS), test.local, test.remote)
+			}
+			for option, o := range c.options {
+				if o.us == optWantYes || o.us == optWantNo || o.him == optWantYes || o.him == optWantNo {
+					t.Errorf("negotiation of option %d still pending", option)
+				}
+			}
+		})
+	}
+}
+
+// A client that keeps answering our DO with WILL must not cause a negotiation loop.
+func TestNegotiationNoLoop(t *testing.T) {
+	c, fake := newTestConn(IAC, cmdWill, OptNAWS, IAC, cmdWill, OptNAWS, IAC, cmdWill, OptNAWS)
+	c.SupportOption(OptNAWS, false, true)
+	err := c.EnableRemote(OptNAWS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readAll(c)
+	if sent := fake.written(); !bytes.Equal(sent, []byte{IAC, cmdDo, OptNAWS}) {
+		t.Errorf("sent %v, want a single DO NAWS", sent)
+	}
+	if !c.IsEnabledRemote(OptNAWS) {
+		t.Errorf("NAWS not enabled")
+	}
+}
+
+// When the client confirms an option we disabled ourselves, the option has to stop working on our side too.
+func TestConfirmedDisableStopsOption(t *testing.T) {
+	c, fake := newTestConn(IAC, cmdDo, OptMCCP2)
+	c.SupportOption(OptMCCP2, true, false)
+	readAll(c)
+	if c.compressor == nil {
+		t.Fatal("compression not started")
+	}
+	err := c.DisableLocal(OptMCCP2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.in.Write([]byte{IAC, cmdDont, OptMCCP2})
+	readAll(c)
+	if c.IsEnabledLocal(OptMCCP2) {
+		t.Error("MCCP2 still enabled")
+	}
+	if c.compressor != nil {
+		t.Error("compression not stopped")
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"io"
+	"net"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Telnet START_TLS option, see: https://tools.ietf.org/html/draft-altman-telnet-starttls-02
+// We send DO START_TLS, the client answers WILL START_TLS. Both sides send IAC SB START_TLS FOLLOWS IAC SE, and the
+// client starts the TLS handshake right after its own FOLLOWS.
+
+const startTLSFollows byte = 1
+
+// the TLS connection reads the data we already received after the FOLLOWS first, as it's part of the handshake.
+type prefixConn struct {
+	net.Conn
+	reader io.Reader
+}
+
+func (p *prefixConn) Read(data []byte) (int, error) {
+	return p.reader.Read(data)
+}
+
+// StartTLS asks the client to switch to TLS. Call this before requesting any other option, as everything sent before
+// is unencrypted. Returns true when the connection is encrypted, false if the client refused or the handshake failed.
+// A failed handshake leaves the connection in an unusable state, so it should be closed.
+func (c *Conn) StartTLS(config *tls.Config, timeout time.Duration) (encrypted bool, err error) {
+	c.SupportOption(OptStartTLS, false, true)
+	err = c.EnableRemote(OptStartTLS)
+	if err != nil {
+		return
+	}
+	buf := make([]byte, 1024)
+	deadline := time.Now().Add(timeout)
+	err = c.SetReadDeadline(deadline)
+	if err != nil {
+		return
+	}
+	// wait for the FOLLOWS of the client. Any data received before is discarded.
+	for !c.startTLS && !c.startTLSRefused() {
+		_, err = c.Read(buf)
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			// clients that don't know the option might not answer at all
+			break
+		}
+		if err != nil {
+			return
+		}
+	}
+	if !c.startTLS {
+		log.Debugf("%s - Client refused START_TLS", c.RemoteAddr())
+		err = c.SetReadDeadline(time.Time{})
+		return
+	}
+	c.startTLS = false
+
+	// everything we read from now on is encrypted
+	raw := &prefixConn{
+		Conn:   c.Conn,
+		reader: io.MultiReader(bytes.NewReader(c.pending), c.in),
+	}
+	tlsConn := tls.Server(raw, config)
+	err = tlsConn.SetDeadline(deadline)
+	if err != nil {
+		return
+	}
+	err = tlsConn.Handshake()
+	if err != nil {
+		return
+	}
+	err = tlsConn.SetDeadline(time.Time{})
+	if err != nil {
+		return
+	}
+	c.writeLock.Lock()
+	c.Conn = tlsConn
+	c.writeLock.Unlock()
+	c.in = bufio.NewReader(tlsConn)
+	c.pending = nil
+	log.Debugf("%s - START_TLS negotiation successful", c.RemoteAddr())
+	return true, nil
+}
+
+func (c *Conn) startTLSRefused() bool {
+	c.optionLock.Lock()
+	defer c.optionLock.Unlock()
+	return c.options[OptStartTLS].him == optNo
+}
+
+func (c *Conn) sendStartTLSFollows() {
+	err := c.sendSubNegotiation(OptStartTLS, []byte{startTLSFollows})
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
+
+func (c *Conn) startTLSReceived(data []byte) {
+	if len(data) != 1 || data[0] != startTLSFollows {
+		log.Errorf("%s - Incorrect START_TLS subnegotiation.", c.RemoteAddr())
+		return
+	}
+	if !c.IsEnabledRemote(OptStartTLS) {
+		log.Errorf("%s - Received START_TLS subnegotiation, but START_TLS was not negotiated.", c.RemoteAddr())
+		return
+	}
+	c.startTLS = true
+}
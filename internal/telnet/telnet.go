@@ -17,9 +17,16 @@
 package telnet
 
 import (
+	"bufio"
 	"bytes"
+	"compress/zlib"
 	"encoding/binary"
+	"io"
 	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -67,18 +74,45 @@ const (
 
 	// telnet options
 
-	optBinary     byte = 0
-	optEcho       byte = 1
-	optSupressGA  byte = 3
-	optMsgSizeNeg byte = 4
-	optStatus     byte = 5
-	optTimingMark byte = 6
-	optNAWS       byte = 31
+	OptBinary     byte = 0
+	OptEcho       byte = 1
+	OptSuppressGA byte = 3
+	OptMsgSizeNeg byte = 4
+	OptStatus     byte = 5
+	OptTimingMark byte = 6
+	OptTermType   byte = 24
+	OptNAWS       byte = 31
+	OptLinemode   byte = 34
+	OptNewEnviron byte = 39
+	OptCharset    byte = 42
+	OptStartTLS   byte = 46
+	OptMSDP       byte = 69
+	OptMCCP2      byte = 86
+	OptMCCP3      byte = 87
+	OptGMCP       byte = 201
+
+	// terminal-type subnegotiation commands
+
+	ttypeIs   byte = 0
+	ttypeSend byte = 1
+
+	// stop asking for more terminal types after this many replies, in case a client never repeats itself
+	maxTermTypes = 8
+
+	// control characters we pass to the terminal for erase character and erase line
+	chBackspace byte = 8
+	chEraseLine byte = 21 // Ctrl-U
+
+	// our answer to "Are You There"
+	aytResponse = "\r\n[Yes]\r\n"
 )
 
 // Telnet specific connection stuff
 
 type Conn struct {
+	// time we last received data (unix nanoseconds). Accessed atomically, so it needs to be the first field
+	// to be 64-bit aligned on 32-bit platforms.
+	activity int64
 	net.Conn
 	// used during telnet command processing. See ConnectionState constants above.
 	// We need to store the state across multiple reads. This seemed like a good spot.
@@ -87,6 +121,42 @@ type Conn struct {
 	subNegBuffer bytes.Buffer
 	// term info we received
 	resizeHandler func(int, int)
+	// terminal types received so far, and handler to call when the list is complete (RFC 1091 and MTTS)
+	termTypes       []string
+	termTypeDone    bool
+	termTypeHandler func([]string, int)
+	// handlers for out-of-band data, see oob.go
+	gmcpHandler func(string, []byte)
+	msdpHandler func(string, interface{})
+	// environment variables and character set, see environ.go and charset.go
+	environ          map[string]string
+	environPending   bool
+	environHandler   func(map[string]string)
+	charset          string
+	charsetRequested bool
+	charsetPending   bool
+	charsetHandler   func(string)
+	// called when the client sends Interrupt Process or Break
+	interruptHandler func()
+	// closed when the connection is closed, so background tasks like the keepalive stop
+	done      chan struct{}
+	closeOnce sync.Once
+	// option negotiation state for every possible option (RFC 1143), see option.go
+	options    [256]optionEntry
+	optionLock sync.Mutex
+	// buffered reader on the connection, and data that still needs processing before we read from it again.
+	in      *bufio.Reader
+	pending []byte
+	// MCCP2/MCCP3 compression state, see compress.go
+	writeLock         sync.Mutex
+	compressor        *zlib.Writer
+	compressedOut     *countingWriter
+	uncompressedCount int64
+	reportedSaved     int64
+	decompressor      io.ReadCloser
+	startDecompress   bool
+	// the client starts the TLS handshake after its START_TLS FOLLOWS, see starttls.go
+	startTLS bool
 }
 
 func (c *Conn) SendCommand(cmd byte) error {
@@ -94,7 +164,7 @@ func (c *Conn) SendCommand(cmd byte) error {
 		IAC,
 		cmd,
 	}
-	_, err := c.Conn.Write(buffer)
+	_, err := c.rawWrite(buffer)
 	return err
 }
 
@@ -106,7 +176,7 @@ func (c *Conn) SendWill(o byte) error {
 		cmd,
 		o,
 	}
-	_, err := c.Conn.Write(buffer)
+	_, err := c.rawWrite(buffer)
 	return err
 }
 
@@ -118,7 +188,7 @@ func (c *Conn) SendWont(o byte) error {
 		cmd,
 		o,
 	}
-	_, err := c.Conn.Write(buffer)
+	_, err := c.rawWrite(buffer)
 	return err
 }
 
@@ -130,7 +200,7 @@ func (c *Conn) SendDo(o byte) error {
 		cmd,
 		o,
 	}
-	_, err := c.Conn.Write(buffer)
+	_, err := c.rawWrite(buffer)
 	return err
 }
 
@@ -142,7 +212,7 @@ func (c *Conn) SendDont(o byte) error {
 		cmd,
 		o,
 	}
-	_, err := c.Conn.Write(buffer)
+	_, err := c.rawWrite(buffer)
 	return err
 }
 
@@ -155,19 +225,19 @@ func (c *Conn) Write(data []byte) (totalWritten int, err error) {
 		var currentWritten int
 		index := bytes.IndexByte(data, IAC)
 		if index == -1 {
-			currentWritten, err = c.Conn.Write(data)
+			currentWritten, err = c.rawWrite(data)
 			totalWritten += currentWritten
 			break
 		} else {
 			// write everything before the IAC byte
-			currentWritten, err = c.Conn.Write(data[:index])
+			currentWritten, err = c.rawWrite(data[:index])
 			totalWritten += currentWritten
 			if err != nil {
 				log.Errorln(err.Error())
 				break
 			}
 			// write double IAC for escaping purposes
-			currentWritten, err = c.Conn.Write([]byte{IAC, IAC})
+			currentWritten, err = c.rawWrite([]byte{IAC, IAC})
 			// not sure if we should account for the fact that more data is written than the original buffer
 			// (because of the IAC doubling). TO-DO: Investigate later.
 			totalWritten += currentWritten
@@ -187,7 +257,22 @@ func (c *Conn) Write(data []byte) (totalWritten int, err error) {
 func (c *Conn) Read(data []byte) (int, error) {
 	destIndex := 0
 	buffer := make([]byte, len(data)) // make a new buffer for reading data, same size as original one
-	tempRead, err := c.Conn.Read(buffer)
+	source, err := c.input()
+	if err != nil {
+		return 0, err
+	}
+	compressed := c.decompressor != nil
+	tempRead, err := source.Read(buffer)
+	if tempRead > 0 {
+		c.touch()
+	}
+	if compressed && err == io.EOF {
+		// the client ended the compressed stream, continue reading uncompressed data.
+		log.Debugf("%s - MCCP3 decompression stopped", c.RemoteAddr())
+		_ = c.decompressor.Close()
+		c.decompressor = nil
+		err = nil
+	}
 	// process all bytes that have been read, even if an error occurred.
 	// This seems to be a recommended approach:
 	//
@@ -197,7 +282,8 @@ func (c *Conn) Read(data []byte) (int, error) {
 	// Doing so correctly handles I/O errors that happen after reading some bytes and also both of the allowed EOF behaviors.
 	//
 	if tempRead > 0 {
-		for _, element := range buffer[:tempRead] {
+	processLoop:
+		for index, element := range buffer[:tempRead] {
 			switch c.readState {
 			case stateData:
 				switch element {
@@ -207,8 +293,11 @@ func (c *Conn) Read(data []byte) (int, error) {
 				case chCR:
 					data[destIndex] = element
 					destIndex++
-					log.Tracef("%s - State changed to stateCR", c.RemoteAddr())
-					c.readState = stateCR
+					// in binary mode, CR has no special meaning and is passed as-is
+					if !c.IsEnabledRemote(OptBinary) {
+						log.Tracef("%s - State changed to stateCR", c.RemoteAddr())
+						c.readState = stateCR
+					}
 				default:
 					// not IAC or CR, so it's data
 					data[destIndex] = element
@@ -216,20 +305,27 @@ func (c *Conn) Read(data []byte) (int, error) {
 				}
 
 			case stateCR:
-				// only for conversion from CR/LF or CR/NUL to CR. We probably shouldn't do this if telnet is in binary mode.
-				// TO-DO implement binary mode support :-D
+				// only for conversion from CR/LF or CR/NUL to CR. We never end up here when the client is in binary mode.
 				switch element {
 				case chLF, chNUL:
 					// Do nothing. just ignore this byte.
+					log.Tracef("%s - State changed to stateData", c.RemoteAddr())
+					c.readState = stateData
+				case IAC:
+					// a bare CR followed by a telnet command
+					log.Tracef("%s - State changed to stateCommand", c.RemoteAddr())
+					c.readState = stateCommand
+				case chCR:
+					// another CR, stay in this state
+					data[destIndex] = element
+					destIndex++
 				default:
 					// Add to buffer, as it's just normal data
 					data[destIndex] = element
 					destIndex++
-
+					log.Tracef("%s - State changed to stateData", c.RemoteAddr())
+					c.readState = stateData
 				}
-				// switch back to normal data state
-				log.Tracef("%s - State changed to stateData", c.RemoteAddr())
-				c.readState = stateData
 
 			case stateCommand:
 				if element == IAC {
@@ -260,7 +356,12 @@ func (c *Conn) Read(data []byte) (int, error) {
 						c.subNegBuffer.Reset()
 					default:
 						log.Debugf("%s - Received telnet command: %d", c.RemoteAddr(), element)
-						c.commandHandler(element)
+						// some commands are translated to control characters for the terminal.
+						// There's always room for it, as the command itself took two bytes.
+						if ch, ok := c.commandHandler(element); ok {
+							data[destIndex] = ch
+							destIndex++
+						}
 						log.Tracef("%s - State changed to stateData", c.RemoteAddr())
 						c.readState = stateData
 					}
@@ -322,6 +423,13 @@ func (c *Conn) Read(data []byte) (int, error) {
 					c.subNegHandler()
 					log.Tracef("%s - State changed to stateData", c.RemoteAddr())
 					c.readState = stateData
+					if c.startDecompress || c.startTLS {
+						// everything after this subnegotiation is compressed (MCCP3) or encrypted (START_TLS).
+						// Keep the rest for the decompressor or the TLS handshake.
+						rest := append([]byte{}, buffer[index+1:tempRead]...)
+						c.pending = append(rest, c.pending...)
+						break processLoop
+					}
 				}
 
 			}
@@ -332,27 +440,108 @@ func (c *Conn) Read(data []byte) (int, error) {
 }
 
 func (c *Conn) RequestTermSize() {
-	err := c.SendDo(optNAWS)
+	err := c.EnableRemote(OptNAWS)
 	if err != nil {
 		log.Errorln(err.Error())
 	}
 }
 
+// RequestBinary asks for TRANSMIT-BINARY (RFC 856) in both directions, so 8-bit data (CP437 art, file transfers) is
+// passed through unmodified.
+func (c *Conn) RequestBinary() {
+	err := c.EnableLocal(OptBinary)
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+	err = c.EnableRemote(OptBinary)
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
+
+// IsBinary returns true if binary mode is active in both directions.
+func (c *Conn) IsBinary() bool {
+	return c.IsEnabledLocal(OptBinary) && c.IsEnabledRemote(OptBinary)
+}
+
+func (c *Conn) RequestTermType() {
+	err := c.EnableRemote(OptTermType)
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
+
+// Negotiate reads from the connection until all pending option negotiations are finished, or until the timeout expires.
+// Any data received during this phase is discarded.
+func (c *Conn) Negotiate(timeout time.Duration) {
+	buf := make([]byte, 1024)
+	deadline := time.Now().Add(timeout)
+	for c.negotiationPending() {
+		err := c.SetReadDeadline(deadline)
+		if err != nil {
+			log.Errorln(err.Error())
+			break
+		}
+		_, err = c.Read(buf)
+		if err != nil {
+			log.Debugf("%s - Stopped waiting for option negotiation: %s", c.RemoteAddr(), err.Error())
+			break
+		}
+	}
+	// remove deadline again
+	err := c.SetReadDeadline(time.Time{})
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
+
+func (c *Conn) negotiationPending() bool {
+	c.optionLock.Lock()
+	defer c.optionLock.Unlock()
+	for _, o := range c.options {
+		if o.us == optWantYes || o.us == optWantNo || o.him == optWantYes || o.him == optWantNo {
+			return true
+		}
+	}
+	// some options need a subnegotiation before we have the information we want
+	if c.options[OptTermType].him == optYes && !c.termTypeDone {
+		return true
+	}
+	return c.environPending || c.charsetPending
+}
+
 // create and initialize telnet connection object
 func NewConnection(c net.Conn) *Conn {
 	conn := Conn{
 		Conn: c,
+		in:   bufio.NewReader(c),
+		done: make(chan struct{}),
 	}
+	// options we agree to when the client asks for them.
+	// Echo is never allowed on the client side, we want to be in control of all echo-ing.
+	conn.SupportOption(OptSuppressGA, true, true)
+	conn.SupportOption(OptEcho, true, false)
+	conn.SupportOption(OptNAWS, false, true)
+	conn.SupportOption(OptTermType, false, true)
+	conn.SupportOption(OptBinary, true, true)
+	conn.SupportOption(OptMCCP2, true, false)
+	conn.SupportOption(OptMCCP3, true, false)
+	conn.SupportOption(OptGMCP, true, false)
+	conn.SupportOption(OptMSDP, true, false)
+	conn.SupportOption(OptNewEnviron, false, true)
+	conn.SupportOption(OptCharset, true, true)
+	conn.SupportOption(OptLinemode, false, true)
+
 	// set telnet parameters, this should ensure the connection is in character-mode, and echo'ing is done by the server.
-	// We want to be in control of all echo-ing.
-	err := conn.SendWill(optSupressGA)
+	err := conn.EnableLocal(OptSuppressGA)
 	if err != nil {
 		log.Errorln(err.Error())
 	}
-	err = conn.SendWill(optEcho)
+	err = conn.EnableLocal(OptEcho)
 	if err != nil {
 		log.Errorln(err.Error())
 	}
+	conn.RequestBinary()
 
 	return &conn
 }
@@ -366,7 +555,7 @@ func (c *Conn) subNegHandler() {
 	if len(data) > 0 {
 		option := data[0]
 		switch option {
-		case optNAWS:
+		case OptNAWS:
 			if len(data) != 5 {
 				log.Errorf("%s - Incorrect amount of parameters for NAWS subnegotiation.", c.RemoteAddr())
 				break
@@ -379,6 +568,31 @@ func (c *Conn) subNegHandler() {
 				c.resizeHandler(int(w), int(h))
 			}
 			log.Debugf("%s - terminal size update received (w=%d, h=%d)", c.RemoteAddr(), w, h)
+		case OptTermType:
+			if len(data) < 2 || data[1] != ttypeIs {
+				log.Errorf("%s - Incorrect terminal-type subnegotiation.", c.RemoteAddr())
+				break
+			}
+			c.termTypeReceived(string(data[2:]))
+		case OptMCCP3:
+			if !c.IsEnabledLocal(OptMCCP3) {
+				log.Errorf("%s - Received MCCP3 subnegotiation, but MCCP3 was not negotiated.", c.RemoteAddr())
+				break
+			}
+			// the client starts compressing right after this subnegotiation
+			c.startDecompress = true
+		case OptStartTLS:
+			c.startTLSReceived(data[1:])
+		case OptNewEnviron:
+			c.environReceived(data[1:])
+		case OptCharset:
+			c.charsetReceived(data[1:])
+		case OptLinemode:
+			c.linemodeReceived(data[1:])
+		case OptGMCP:
+			c.gmcpReceived(data[1:])
+		case OptMSDP:
+			c.msdpReceived(data[1:])
 		default:
 			log.Debugf("%s - Unknown subnegotation received (%d). Ignoring.", c.RemoteAddr(), option)
 		}
@@ -393,37 +607,159 @@ func (c *Conn) InstallResizeHandler(handler func(int, int)) {
 
 }
 
-func (c *Conn) commandHandler(command byte) {
-	// TO-DO: implement if necessary
+// The handler receives all terminal types reported by the client, and the MTTS bitfield (zero if not supported).
+func (c *Conn) InstallTermTypeHandler(handler func([]string, int)) {
+	c.termTypeHandler = handler
+}
+
+func (c *Conn) sendTermTypeRequest() {
+	buffer := []byte{
+		IAC,
+		cmdSB,
+		OptTermType,
+		ttypeSend,
+		IAC,
+		cmdSE,
+	}
+	_, err := c.rawWrite(buffer)
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
+
+// Every request for the terminal type returns the next entry in the list of the client. When the end of the list
+// is reached, the client repeats the last entry (or starts over). MTTS clients report their capabilities in the third
+// entry as "MTTS <bitfield>".
+func (c *Conn) termTypeReceived(name string) {
+	if c.termTypeDone {
+		return
+	}
+	log.Debugf("%s - terminal type received: %s", c.RemoteAddr(), name)
+	count := len(c.termTypes)
+	if count > 0 && (name == c.termTypes[count-1] || name == c.termTypes[0]) {
+		c.termTypeFinished(0)
+		return
+	}
+	if strings.HasPrefix(strings.ToUpper(name), "MTTS ") {
+		mtts, err := strconv.Atoi(strings.TrimSpace(name[5:]))
+		if err != nil {
+			log.Errorf("%s - Invalid MTTS value received: %s", c.RemoteAddr(), name)
+		}
+		c.termTypeFinished(mtts)
+		return
+	}
+	c.termTypes = append(c.termTypes, name)
+	if len(c.termTypes) >= maxTermTypes {
+		c.termTypeFinished(0)
+		return
+	}
+	c.sendTermTypeRequest()
+}
+
+func (c *Conn) termTypeFinished(mtts int) {
+	c.termTypeDone = true
+	log.Debugf("%s - terminal types: %v (MTTS: %d)", c.RemoteAddr(), c.termTypes, mtts)
+	if c.termTypeHandler != nil {
+		c.termTypeHandler(c.termTypes, mtts)
+	}
+}
+
+// The handler is called from the read loop, so it shouldn't block.
+func (c *Conn) InstallInterruptHandler(handler func()) {
+	c.interruptHandler = handler
+}
+
+// Handles telnet commands. If the command should be passed to the terminal as a control character,
+// the character is returned.
+func (c *Conn) commandHandler(command byte) (ch byte, ok bool) {
+	switch command {
+	case cmdAYT:
+		_, err := c.Write([]byte(aytResponse))
+		if err != nil {
+			log.Errorln(err.Error())
+		}
+	case cmdIP, cmdBreak:
+		if c.interruptHandler != nil {
+			c.interruptHandler()
+		}
+	case cmdEraseChar:
+		return chBackspace, true
+	case cmdEraseLine:
+		return chEraseLine, true
+	case cmdNop, cmdData, cmdGA, cmdAbort:
+		// nothing to do
+	default:
+		log.Debugf("%s - Unknown telnet command received (%d). Ignoring.", c.RemoteAddr(), command)
+	}
+	return 0, false
 }
 
-// I doubt this is correct behaviour. I should investigate the Q Method (RFC1143) for option negotiation.
+// Called by the read loop for every WILL/WONT/DO/DONT we receive. The actual negotiation is done by the
+// Q Method state machine in option.go, so we never answer a request blindly.
 func (c *Conn) optionHandler(command byte, option byte) {
+	if option == OptTimingMark {
+		c.timingMarkHandler(command)
+		return
+	}
+	var changed bool
 	var err error
 	switch command {
 	case cmdWill:
-		switch option {
-		// only send DO for options we actually support
-		case optNAWS, optSupressGA:
-			err = c.SendDo(option)
-		case optEcho:
-			// explicitly disable local echo on client for now? Should this be allowed if client requests it?
-			err = c.SendDont(option)
-		default:
-			err = c.SendDont(option)
-		}
+		changed, err = c.receiveWill(option)
 	case cmdWont:
+		changed, err = c.receiveWont(option)
 	case cmdDo:
-		switch option {
-		case optSupressGA, optEcho:
-			err = c.SendWill(option)
-		default:
-			err = c.SendWont(option)
-
-		}
+		changed, err = c.receiveDo(option)
 	case cmdDont:
+		changed, err = c.receiveDont(option)
 	}
 	if err != nil {
 		log.Errorln(err.Error())
 	}
+	if changed {
+		local := command == cmdDo || command == cmdDont
+		// not the command: a WILL that answers our DONT still disables the option
+		enabled := c.IsEnabledRemote(option)
+		if local {
+			enabled = c.IsEnabledLocal(option)
+		}
+		log.Debugf("%s - Option %d changed (local=%t, enabled=%t)", c.RemoteAddr(), option, local, enabled)
+		c.optionChanged(option, local, enabled)
+	}
+}
+
+// Called when an option has been enabled or disabled by the client. Options that need to take action
+// when they become active (eg: start a subnegotiation) should do so here.
+func (c *Conn) optionChanged(option byte, local bool, enabled bool) {
+	switch option {
+	case OptTermType:
+		if !local && enabled {
+			c.termTypes = nil
+			c.termTypeDone = false
+			c.sendTermTypeRequest()
+		}
+	case OptNewEnviron:
+		if !local && enabled {
+			c.sendEnvironRequest()
+		}
+	case OptCharset:
+		// the RFC allows either side to enable CHARSET. We only send our request once.
+		if enabled {
+			c.sendCharsetRequest()
+		}
+	case OptLinemode:
+		if !local && enabled {
+			c.sendLinemodeMode(linemodeWantedMode)
+		}
+	case OptStartTLS:
+		if !local && enabled {
+			c.sendStartTLSFollows()
+		}
+	case OptMCCP2:
+		if local && enabled {
+			c.startCompression()
+		} else if local {
+			c.stopCompression()
+		}
+	}
 }
@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package telnet
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeConn is a connection that reads from a fixed input, and records everything that is written to it.
+type fakeConn struct {
+	net.Conn
+	lock sync.Mutex
+	in   bytes.Buffer
+	out  bytes.Buffer
+}
+
+func (f *fakeConn) Read(data []byte) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.in.Len() == 0 {
+		return 0, io.EOF
+	}
+	return f.in.Read(data)
+}
+
+func (f *fakeConn) Write(data []byte) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.out.Write(data)
+}
+
+func (f *fakeConn) Close() error                       { return nil }
+func (f *fakeConn) RemoteAddr() net.Addr               { return &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1234} }
+func (f *fakeConn) SetDeadline(t time.Time) error      { return nil }
+func (f *fakeConn) SetReadDeadline(t time.Time) error  { return nil }
+func (f *fakeConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// written returns the data written so far, and clears it.
+func (f *fakeConn) written() []byte {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	result := append([]byte{}, f.out.Bytes()...)
+	f.out.Reset()
+	return result
+}
+
+// newTestConn returns a connection that reads the input, as if it was sent by the client. The negotiation that
+// NewConnection starts is forgotten, so every option is disabled and unsupported.
+func newTestConn(input ...byte) (*Conn, *fakeConn) {
+	fake := &fakeConn{}
+	c := NewConnection(fake)
+	c.options = [256]optionEntry{}
+	fake.written()
+	fake.in.Write(input)
+	return c, fake
+}
+
+// readAll reads until the input is used up, and returns the data that wasn't a telnet command.
+func readAll(c *Conn) []byte {
+	var result []byte
+	buf := make([]byte, 64)
+	for {
+		n, err := c.Read(buf)
+		result = append(result, buf[:n]...)
+		if err != nil {
+			return result
+		}
+	}
+}
+
+func TestReadUnescapesData(t *testing.T) {
+	tests := map[string]struct {
+		input []byte
+		want  []byte
+	}{
+		"plain":                 {[]byte("hello"), []byte("hello")},
+		"escaped IAC":           {[]byte{'a', IAC, IAC, 'b'}, []byte{'a', IAC, 'b'}},
+		"CR LF":                 {[]byte{'a', chCR, chLF, 'b'}, []byte{'a', chCR, 'b'}},
+		"CR NUL":                {[]byte{'a', chCR, chNUL, 'b'}, []byte{'a', chCR, 'b'}},
+		"command":               {[]byte{'a', IAC, cmdNop, 'b'}, []byte{'a', 'b'}},
+		"option":                {[]byte{'a', IAC, cmdWont, OptEcho, 'b'}, []byte{'a', 'b'}},
+		"subnegotiation":        {[]byte{'a', IAC, cmdSB, 99, 1, 2, IAC, cmdSE, 'b'}, []byte{'a', 'b'}},
+		"escaped IAC in subneg": {[]byte{'a', IAC, cmdSB, 99, IAC, IAC, IAC, cmdSE, 'b'}, []byte{'a', 'b'}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newTestConn(test.input...)
+			if got := readAll(c); !bytes.Equal(got, test.want) {
+				t.Errorf("read %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package termserve
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jeroenjacobs79/tobw/internal/config"
+	"github.com/jeroenjacobs79/tobw/internal/guard"
+)
+
+// Connection limits of a listener. Every address has a token bucket: a new connection takes a token, and tokens are
+// added at the configured rate, up to the burst size. There is also a cap on the open connections per address, and
+// on all open connections of the listener. rlogin peers we trust connect for many users, only the listener cap
+// applies to them.
+
+// how often buckets that are full again are removed
+const limiterCleanupInterval = 1 * time.Minute
+
+// reasons for refusing a connection, also used as metric label
+const (
+	refusedRate  = "rate"
+	refusedPerIP = "per_ip"
+	refusedTotal = "total"
+)
+
+var refusalMessages = map[string]string{
+	refusedRate:  "Too many connections from your address. Please wait a minute and try again.\r\n",
+	refusedPerIP: "You have too many connections open. Please close one and try again.\r\n",
+	refusedTotal: "The server is busy. Please try again later.\r\n",
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type connectionLimiter struct {
+	limits       config.LimitsConfig
+	trustedPeers []*net.IPNet
+	lock         sync.Mutex
+	buckets      map[string]*tokenBucket
+	open         map[string]int
+	total        int
+	lastCleanup  time.Time
+}
+
+func newConnectionLimiter(listener config.Listener) *connectionLimiter {
+	return &connectionLimiter{
+		limits:       listener.Limits,
+		trustedPeers: listener.TrustedPeers,
+		buckets:      make(map[string]*tokenBucket),
+		open:         make(map[string]int),
+		lastCleanup:  time.Now(),
+	}
+}
+
+// acquire reserves a connection for the address. If the connection is refused, the reason is returned.
+// Call release when an accepted connection is closed.
+func (l *connectionLimiter) acquire(addr net.Addr) (reason string, ok bool) {
+	key := guard.OriginKey(addr.String())
+	trusted := isTrustedPeer(addr, l.trustedPeers)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := time.Now()
+	l.cleanup(now)
+
+	if l.limits.MaxConnections > 0 && l.total >= l.limits.MaxConnections {
+		return refusedTotal, false
+	}
+	if !trusted {
+		if l.limits.MaxPerIP > 0 && l.open[key] >= l.limits.MaxPerIP {
+			return refusedPerIP, false
+		}
+		if l.limits.Rate > 0 {
+			bucket := l.buckets[key]
+			if bucket == nil {
+				bucket = &tokenBucket{tokens: float64(l.limits.Burst), last: now}
+				l.buckets[key] = bucket
+			}
+			l.refill(bucket, now)
+			if bucket.tokens < 1 {
+				return refusedRate, false
+			}
+			bucket.tokens--
+		}
+	}
+	l.open[key]++
+	l.total++
+	return "", true
+}
+
+func (l *connectionLimiter) release(addr net.Addr) {
+	key := guard.OriginKey(addr.String())
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.total--
+	l.open[key]--
+	if l.open[key] <= 0 {
+		delete(l.open, key)
+	}
+}
+
+// call with the lock held
+func (l *connectionLimiter) refill(bucket *tokenBucket, now time.Time) {
+	bucket.tokens += now.Sub(bucket.last).Minutes() * l.limits.Rate
+	if bucket.tokens > float64(l.limits.Burst) {
+		bucket.tokens = float64(l.limits.Burst)
+	}
+	bucket.last = now
+}
+
+// removes the buckets of addresses that didn't connect for a while, they're the same as a new bucket.
+// Call with the lock held.
+func (l *connectionLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < limiterCleanupInterval {
+		return
+	}
+	l.lastCleanup = now
+	for key, bucket := range l.buckets {
+		l.refill(bucket, now)
+		if bucket.tokens >= float64(l.limits.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package termserve
+
+import (
+	"net"
+
+	"github.com/jeroenjacobs79/tobw/internal/ansiterm"
+	"github.com/jeroenjacobs79/tobw/internal/config"
+	"github.com/jeroenjacobs79/tobw/internal/rlogin"
+	"github.com/jeroenjacobs79/tobw/internal/session"
+	"github.com/jeroenjacobs79/tobw/internal/user"
+	log "github.com/sirupsen/logrus"
+)
+
+// rlogin connection handling. BBS front-ends use this to hand off their players. Users of trusted peers are logged
+// in without password, everybody else gets the normal login prompt with the username filled in.
+
+func isTrustedPeer(addr net.Addr, trustedPeers []*net.IPNet) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, network := range trustedPeers {
+		if network.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+func handleRLoginRequest(conn net.Conn, listener config.Listener) {
+	rloginConn := rlogin.NewConnection(conn)
+	log.Infof("%s - Connected", rloginConn.RemoteAddr())
+	err := rloginConn.Handshake(rloginHandshakeTimeout)
+	if err != nil {
+		log.Errorf("%s - rlogin handshake failed: %s", rloginConn.RemoteAddr(), err.Error())
+		err = rloginConn.Close()
+		if err != nil {
+			log.Errorln(err.Error())
+		}
+		return
+	}
+
+	term := ansiterm.CreateAnsiTerminal(rloginConn)
+	term.SetCp437toUtf8(listener.ConvertUTF8)
+	if rloginConn.TerminalType != "" {
+		term.SetTerminalType([]string{rloginConn.TerminalType}, 0)
+	}
+	rloginConn.InstallResizeHandler(term.ResizeTerminal)
+	currentSession := session.CreateSession(term, config.TCPRLogin, conn.RemoteAddr().String())
+
+	// the server user is the account the client wants to use
+	username := rloginConn.ServerUser
+	if username == "" {
+		username = rloginConn.ClientUser
+	}
+	currentSession.Setenv("USER", username)
+	if isTrustedPeer(conn.RemoteAddr(), listener.TrustedPeers) {
+		currentSession.User = user.Find(username)
+		if currentSession.User != nil {
+			log.Infof("%s - rlogin user %s logged in by trusted peer", conn.RemoteAddr(), currentSession.User.Username)
+		}
+	}
+
+	session.Start(sessionContext, currentSession)
+}
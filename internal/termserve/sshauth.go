@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package termserve
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jeroenjacobs79/tobw/internal/config"
+	"github.com/jeroenjacobs79/tobw/internal/guard"
+	"github.com/jeroenjacobs79/tobw/internal/user"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+// ssh authentication. Users authenticate with the same credentials they use in the game, or with a public key
+// they registered in the game. The anonymous user doesn't need to authenticate, and gets the login prompt of the game.
+
+const (
+	// permission extensions, so we know who logged in when the session starts
+	extUsername  = "tobw-username"
+	extAnonymous = "tobw-anonymous"
+)
+
+func isAnonymousSSHUser(conn ssh.ConnMetadata) bool {
+	return config.AppOptions.SSHAnonymousUser != "" && strings.EqualFold(conn.User(), config.AppOptions.SSHAnonymousUser)
+}
+
+func sshPermissions(u *user.User) *ssh.Permissions {
+	return &ssh.Permissions{
+		Extensions: map[string]string{
+			extUsername: u.Username,
+		},
+	}
+}
+
+func anonymousPermissions() *ssh.Permissions {
+	return &ssh.Permissions{
+		Extensions: map[string]string{
+			extAnonymous: "true",
+		},
+	}
+}
+
+// returns an error if there were too many failed logins for the user or from the address of the client
+func checkSSHLogin(conn ssh.ConnMetadata) error {
+	wait := guard.CheckLogin(conn.User(), conn.RemoteAddr().String())
+	if wait > 0 {
+		return fmt.Errorf("too many failed logins for %s, try again in %s", conn.User(), wait.Round(time.Second))
+	}
+	return nil
+}
+
+func sshPasswordCallback(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+	if isAnonymousSSHUser(conn) {
+		return anonymousPermissions(), nil
+	}
+	err := checkSSHLogin(conn)
+	if err != nil {
+		return nil, err
+	}
+	return sshPasswordLogin(conn, string(password))
+}
+
+func sshPasswordLogin(conn ssh.ConnMetadata, password string) (*ssh.Permissions, error) {
+	u := user.Authenticate(conn.User(), password)
+	if u == nil {
+		log.Infof("%s - ssh password authentication failed for user %s", conn.RemoteAddr(), conn.User())
+		guard.RecordFailure(conn.User(), conn.RemoteAddr().String())
+		return nil, fmt.Errorf("password rejected for %s", conn.User())
+	}
+	// users with two-factor authentication need keyboard-interactive, so we can ask for the code
+	if u.TOTPEnabled() {
+		log.Infof("%s - ssh password authentication without code rejected for user %s", conn.RemoteAddr(), u.Username)
+		return nil, fmt.Errorf("two-factor authentication required for %s", conn.User())
+	}
+	log.Infof("%s - ssh password authentication successful for user %s", conn.RemoteAddr(), u.Username)
+	guard.RecordSuccess(u.Username)
+	return sshPermissions(u), nil
+}
+
+func sshPublicKeyCallback(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	u := user.Find(conn.User())
+	if u == nil || !u.IsAuthorizedKey(key) {
+		log.Debugf("%s - ssh public key %s rejected for user %s", conn.RemoteAddr(), ssh.FingerprintSHA256(key), conn.User())
+		return nil, fmt.Errorf("public key rejected for %s", conn.User())
+	}
+	log.Infof("%s - ssh public key authentication successful for user %s", conn.RemoteAddr(), u.Username)
+	return sshPermissions(u), nil
+}
+
+// Most clients try keyboard-interactive before password. We use it to let the anonymous user in without any questions,
+// and ask for the password for everybody else. Users with two-factor authentication are asked for a code too.
+func sshKeyboardInteractiveCallback(conn ssh.ConnMetadata, client ssh.KeyboardInteractiveChallenge) (*ssh.Permissions, error) {
+	if isAnonymousSSHUser(conn) {
+		return anonymousPermissions(), nil
+	}
+	err := checkSSHLogin(conn)
+	if err != nil {
+		// a challenge without questions shows the message to the user
+		_, _ = client("", "Too many failed logins. Please try again later.", nil, nil)
+		return nil, err
+	}
+	answers, err := client("", "", []string{"Password: "}, []bool{false})
+	if err != nil {
+		return nil, err
+	}
+	if len(answers) != 1 {
+		return nil, fmt.Errorf("unexpected number of answers")
+	}
+	u := user.Find(conn.User())
+	if u == nil || !u.TOTPEnabled() {
+		return sshPasswordLogin(conn, answers[0])
+	}
+	if !u.ValidatePassword(answers[0]) {
+		log.Infof("%s - ssh password authentication failed for user %s", conn.RemoteAddr(), conn.User())
+		guard.RecordFailure(conn.User(), conn.RemoteAddr().String())
+		return nil, fmt.Errorf("password rejected for %s", conn.User())
+	}
+	answers, err = client("", "", []string{"Authenticator or recovery code: "}, []bool{true})
+	if err != nil {
+		return nil, err
+	}
+	if len(answers) != 1 {
+		return nil, fmt.Errorf("unexpected number of answers")
+	}
+	if !u.ValidateSecondFactor(answers[0]) {
+		log.Infof("%s - ssh two-factor authentication failed for user %s", conn.RemoteAddr(), u.Username)
+		guard.RecordFailure(u.Username, conn.RemoteAddr().String())
+		return nil, fmt.Errorf("code rejected for %s", conn.User())
+	}
+	log.Infof("%s - ssh two-factor authentication successful for user %s", conn.RemoteAddr(), u.Username)
+	guard.RecordSuccess(u.Username)
+	return sshPermissions(u), nil
+}
+
+// returns the user that was authenticated during the ssh handshake, or nil for the anonymous user.
+func authenticatedSSHUser(perms *ssh.Permissions) *user.User {
+	if perms == nil {
+		return nil
+	}
+	username, ok := perms.Extensions[extUsername]
+	if !ok {
+		return nil
+	}
+	return user.Find(username)
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package termserve
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+// per-connection state of an ssh client. A connection can have several channels, but only one of them can run the game.
+type sshConnection struct {
+	conn        *ssh.ServerConn
+	maxChannels int
+	lock        sync.Mutex
+	channels    int
+	interactive bool
+	// closed when the connection is gone
+	done chan struct{}
+}
+
+func newSSHConnection(conn *ssh.ServerConn, maxChannels int) *sshConnection {
+	return &sshConnection{
+		conn:        conn,
+		maxChannels: maxChannels,
+		done:        make(chan struct{}),
+	}
+}
+
+// reserves a channel, returns false when the limit is reached
+func (c *sshConnection) openChannel() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.channels >= c.maxChannels {
+		return false
+	}
+	c.channels++
+	return true
+}
+
+func (c *sshConnection) closeChannel() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.channels--
+}
+
+// reserves the interactive session, returns false when another channel already has it
+func (c *sshConnection) claimInteractive() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.interactive {
+		return false
+	}
+	c.interactive = true
+	return true
+}
+
+func (c *sshConnection) releaseInteractive() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.interactive = false
+}
+
+// rejects channels we don't support. Port forwarding and agent forwarding are explicitly prohibited.
+func (c *sshConnection) rejectChannel(newChannel ssh.NewChannel) {
+	var err error
+	switch newChannel.ChannelType() {
+	case "direct-tcpip", "forwarded-tcpip":
+		log.Infof("%s - Rejected port forwarding channel", c.conn.RemoteAddr())
+		err = newChannel.Reject(ssh.Prohibited, "port forwarding is not allowed")
+	case "auth-agent@openssh.com":
+		log.Infof("%s - Rejected agent forwarding channel", c.conn.RemoteAddr())
+		err = newChannel.Reject(ssh.Prohibited, "agent forwarding is not allowed")
+	default:
+		log.Debugf("%s - Rejected unknown channel type %s", c.conn.RemoteAddr(), newChannel.ChannelType())
+		err = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+	}
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
+
+// handles the global requests of the connection. We don't support any, but we log forwarding attempts.
+func (c *sshConnection) handleGlobalRequests(reqs <-chan *ssh.Request) {
+	for req := range reqs {
+		switch req.Type {
+		case "tcpip-forward", "cancel-tcpip-forward", "streamlocal-forward@openssh.com", "cancel-streamlocal-forward@openssh.com":
+			log.Infof("%s - Rejected remote port forwarding request", c.conn.RemoteAddr())
+		default:
+			log.Debugf("%s - Unsupported global request: %s", c.conn.RemoteAddr(), req.Type)
+		}
+		if req.WantReply {
+			err := req.Reply(false, nil)
+			if err != nil {
+				log.Errorln(err.Error())
+			}
+		}
+	}
+}
+
+// Sends keepalive@openssh.com requests. Every client answers them, even if it's a failure reply. The connection is
+// closed when there's no answer within the timeout.
+func (c *sshConnection) keepalive(interval time.Duration, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+
+		log.Tracef("%s - Sending ssh keepalive", c.conn.RemoteAddr())
+		reply := make(chan error, 1)
+		go func() {
+			_, _, err := c.conn.SendRequest("keepalive@openssh.com", true, nil)
+			reply <- err
+		}()
+
+		select {
+		case <-c.done:
+			return
+		case err := <-reply:
+			if err != nil {
+				log.Infof("%s - Keepalive failed: %s", c.conn.RemoteAddr(), err.Error())
+				c.close()
+				return
+			}
+		case <-time.After(timeout):
+			log.Infof("%s - No keepalive response received within %s", c.conn.RemoteAddr(), timeout)
+			c.close()
+			return
+		}
+	}
+}
+
+func (c *sshConnection) close() {
+	err := c.conn.Close()
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package termserve
+
+import (
+	"encoding/binary"
+	"strings"
+
+	"github.com/jeroenjacobs79/tobw/internal/session"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+// Payloads of the session channel requests, see RFC 4254 section 6.
+
+type ptyRequestMsg struct {
+	Term     string
+	Columns  uint32
+	Rows     uint32
+	Width    uint32
+	Height   uint32
+	Modelist string
+}
+
+type windowChangeMsg struct {
+	Columns uint32
+	Rows    uint32
+	Width   uint32
+	Height  uint32
+}
+
+type envRequestMsg struct {
+	Name  string
+	Value string
+}
+
+type execRequestMsg struct {
+	Command string
+}
+
+type subsystemRequestMsg struct {
+	Subsystem string
+}
+
+const (
+	// end of the encoded terminal modes (RFC 4254 section 8)
+	ttyOpEnd byte = 0
+	// opcodes 160 and higher have no argument and end the list
+	ttyOpMaxWithArgument byte = 159
+
+	noCommandsMessage     = "This server only provides interactive sessions, commands and subsystems are not supported.\r\n"
+	oneInteractiveMessage = "Only one interactive session per connection is allowed.\r\n"
+)
+
+// environment variables we accept from the client
+func isAcceptedEnvVar(name string) bool {
+	return name == "LANG" || name == "TERM" || strings.HasPrefix(name, "LC_")
+}
+
+// parses the encoded terminal modes of a pty-req: a list of opcode bytes followed by an uint32 argument.
+func parseTerminalModes(data []byte) map[byte]uint32 {
+	modes := make(map[byte]uint32)
+	for len(data) > 0 {
+		opcode := data[0]
+		if opcode == ttyOpEnd || opcode > ttyOpMaxWithArgument || len(data) < 5 {
+			break
+		}
+		modes[opcode] = binary.BigEndian.Uint32(data[1:5])
+		data = data[5:]
+	}
+	return modes
+}
+
+// Handles the out-of-band requests of a session channel. Sends true on shell when the client wants an interactive
+// shell, or false when the client wants something else or the channel is closed before that.
+func handleSSHChannelRequests(conn *sshConnection, channel ssh.Channel, in <-chan *ssh.Request, currentSession *session.TerminalSession, shell chan<- bool) {
+	remote := conn.conn.RemoteAddr()
+	term := currentSession.Terminal
+	shellRequested := false
+	for req := range in {
+		ok := false
+		switch req.Type {
+		case "pty-req":
+			var msg ptyRequestMsg
+			err := ssh.Unmarshal(req.Payload, &msg)
+			if err != nil {
+				log.Errorf("%s - Invalid pty-req received: %s", remote, err.Error())
+				break
+			}
+			log.Debugf("%s - receive pty-request for terminal %s with size w:%d h:%d", remote, msg.Term, msg.Columns, msg.Rows)
+			term.SetTerminalType([]string{msg.Term}, 0)
+			term.ResizeTerminal(int(msg.Columns), int(msg.Rows))
+			currentSession.SetTerminalModes(parseTerminalModes([]byte(msg.Modelist)))
+			ok = true
+		case "window-change":
+			var msg windowChangeMsg
+			err := ssh.Unmarshal(req.Payload, &msg)
+			if err != nil {
+				log.Errorf("%s - Invalid window-change received: %s", remote, err.Error())
+				break
+			}
+			log.Debugf("%s - receive window-change for terminal size w:%d h:%d", remote, msg.Columns, msg.Rows)
+			term.ResizeTerminal(int(msg.Columns), int(msg.Rows))
+			ok = true
+		case "env":
+			var msg envRequestMsg
+			err := ssh.Unmarshal(req.Payload, &msg)
+			if err != nil {
+				log.Errorf("%s - Invalid env request received: %s", remote, err.Error())
+				break
+			}
+			if !isAcceptedEnvVar(msg.Name) {
+				log.Debugf("%s - Ignoring environment variable %s", remote, msg.Name)
+				break
+			}
+			log.Debugf("%s - Environment variable received: %s=%s", remote, msg.Name, msg.Value)
+			currentSession.Setenv(msg.Name, msg.Value)
+			// only use TERM when the pty-req didn't tell us already
+			if msg.Name == "TERM" && term.GetTerminalType() == "" {
+				term.SetTerminalType([]string{msg.Value}, 0)
+			}
+			if msg.Name == "LANG" {
+				upperLang := strings.ToUpper(msg.Value)
+				if strings.HasSuffix(upperLang, "UTF-8") || strings.HasSuffix(upperLang, "UTF8") {
+					term.SetCp437toUtf8(true)
+				}
+			}
+			ok = true
+		case "shell":
+			if shellRequested {
+				break
+			}
+			if !conn.claimInteractive() {
+				log.Infof("%s - Rejected shell request, there's already an interactive session", remote)
+				writeStderr(channel, oneInteractiveMessage)
+				break
+			}
+			shellRequested = true
+			ok = true
+			shell <- true
+		case "auth-agent-req@openssh.com":
+			log.Infof("%s - Rejected agent forwarding request", remote)
+		case "x11-req":
+			log.Infof("%s - Rejected X11 forwarding request", remote)
+		case "exec", "subsystem":
+			var command string
+			if req.Type == "exec" {
+				var msg execRequestMsg
+				if ssh.Unmarshal(req.Payload, &msg) == nil {
+					command = msg.Command
+				}
+			} else {
+				var msg subsystemRequestMsg
+				if ssh.Unmarshal(req.Payload, &msg) == nil {
+					command = msg.Subsystem
+				}
+			}
+			log.Infof("%s - Rejected %s request: %s", remote, req.Type, command)
+			writeStderr(channel, noCommandsMessage)
+		default:
+			log.Debugf("%s - Unsupported channel request: %s", remote, req.Type)
+		}
+		if req.WantReply {
+			err := req.Reply(ok, nil)
+			if err != nil {
+				log.Errorln(err.Error())
+			}
+		}
+		if !shellRequested && (req.Type == "exec" || req.Type == "subsystem") {
+			shellRequested = true
+			shell <- false
+		}
+	}
+	// channel closed before the client asked for a shell
+	if !shellRequested {
+		shell <- false
+	}
+}
+
+// tells the client why a request was rejected
+func writeStderr(channel ssh.Channel, message string) {
+	_, err := channel.Stderr().Write([]byte(message))
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
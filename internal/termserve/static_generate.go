@@ -0,0 +1,64 @@
+//go:build ignore
+// +build ignore
+
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Generates static.go, which contains the files of the web terminal. Run with go generate after changing the files
+// in the static directory.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strconv"
+)
+
+func main() {
+	var names []string
+	for _, pattern := range []string{"static/*.css", "static/*.js"} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			log.Fatal(err)
+		}
+		names = append(names, matches...)
+	}
+	var out bytes.Buffer
+	out.WriteString("// Code generated by static_generate.go; DO NOT EDIT.\n\n")
+	out.WriteString("package termserve\n\n")
+	out.WriteString("// files served by the websocket listener, by name\n")
+	out.WriteString("var staticFiles = map[string]string{\n")
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(&out, "%s: %s,\n", strconv.Quote(filepath.Base(name)), strconv.Quote(string(data)))
+	}
+	out.WriteString("}\n")
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = ioutil.WriteFile("static.go", source, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -17,160 +17,273 @@
 package termserve
 
 import (
-	"encoding/binary"
-	"io/ioutil"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
 	"net"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/jeroenjacobs79/tobw/internal/ansiterm"
 	"github.com/jeroenjacobs79/tobw/internal/config"
+	"github.com/jeroenjacobs79/tobw/internal/guard"
+	"github.com/jeroenjacobs79/tobw/internal/hostkey"
 	"github.com/jeroenjacobs79/tobw/internal/monitoring"
+	"github.com/jeroenjacobs79/tobw/internal/proxyproto"
 	"github.com/jeroenjacobs79/tobw/internal/session"
 	"github.com/jeroenjacobs79/tobw/internal/telnet"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/crypto/ssh"
 )
 
-var (
-	hangupChannel chan *session.TerminalSession
+const (
+	// maximum time we wait for the client to answer our telnet option requests
+	telnetNegotiationTimeout = 3 * time.Second
+	// maximum time we wait for the TLS handshake of telnets and START_TLS connections
+	tlsHandshakeTimeout = 10 * time.Second
+	// maximum time we wait for the rlogin handshake
+	rloginHandshakeTimeout = 10 * time.Second
+	// maximum time we wait for the PROXY protocol header of the load balancer
+	proxyHeaderTimeout = 10 * time.Second
+	// time to wait before accepting connections again after a temporary error
+	acceptRetryDelay = 1 * time.Second
+	// maximum time we try to tell a refused client why
+	refusalWriteTimeout = 5 * time.Second
+	// time sessions get to end after they're hung up during shutdown
+	hangupTimeout = 5 * time.Second
+
+	shutdownMessage    = "\n\n*** The server is shutting down in %d seconds. Please finish what you're doing. ***\n"
+	shutdownNowMessage = "\n\n*** The server is shutting down now. Goodbye! ***\n"
 )
 
-func init() {
-	// create our channels
-	hangupChannel = make(chan *session.TerminalSession)
-	go hangupTerminalSession(hangupChannel)
-}
+// all sessions end when this is done
+var sessionContext, stopSessions = context.WithCancel(context.Background())
 
-func hangupTerminalSession(sessions <-chan *session.TerminalSession) {
-	for cleanedSession := range sessions {
-		err := cleanedSession.Terminal.Close()
-		log.Infof("%s - Disconnected", cleanedSession.OriginAddress)
-		monitoring.CurrentConnections.Dec()
-		switch cleanedSession.ConnectionType {
-		case config.TCPRaw:
-			monitoring.CurrentRawConnections.Dec()
-		case config.TCPTelnet:
-			monitoring.CurrentTelnetConnections.Dec()
-		case config.TCPSSH:
-			monitoring.CurrentSSHConnections.Dec()
-		default:
-			log.Errorf("Unknown terminal-type detected!")
-		}
-		if err != nil {
-			log.Error(err.Error())
-		}
-		// clear pointer so objects can be garbage-collected
-		cleanedSession.Terminal = nil
-		cleanedSession = nil
-	}
-}
-
-func StartListener(wg *sync.WaitGroup, address string, c config.ConnectionType, cp437ToUtf8 bool) {
+func StartListener(ctx context.Context, wg *sync.WaitGroup, listener config.Listener) {
+	defer wg.Done()
+	address := fmt.Sprintf("%s:%d", listener.Address, listener.Port)
+	c := listener.ListenType
+	cp437ToUtf8 := listener.ConvertUTF8
 	// start telnet listener
 	log.Infof("Starting %s listener on address %s...", c, address)
 
+	// websockets are served over http, see websocket.go
+	if c == config.TCPWebSocket {
+		startWebSocketListener(ctx, listener, address)
+		return
+	}
+
 	// listeners for telnet and raw tcp
 	if c != config.TCPSSH {
-		srv, err := net.Listen("tcp", address)
+		// certificate for telnets and START_TLS
+		var tlsConfig *tls.Config
+		if listener.TLS.Certificate != "" {
+			certificate, err := tls.LoadX509KeyPair(listener.TLS.Certificate, listener.TLS.Key)
+			if err != nil {
+				log.Errorf("Failed to load certificate, %s listener on address %s is disabled: %s", c, address, err.Error())
+				return
+			}
+			tlsConfig = &tls.Config{
+				Certificates: []tls.Certificate{certificate},
+				MinVersion:   tls.VersionTLS12,
+			}
+		}
+		srv, err := listen(listener, address)
 		if err != nil {
 			log.Fatal(err.Error())
 		}
-		// close listener on exit
-		defer func() {
-			err := srv.Close()
-			if err != nil {
-				log.Errorln(err.Error())
-			}
-			wg.Done()
-		}()
 
 		// start accepting connections
 		log.Infof("Started %s listener successfully on address %s.", c, address)
-		for {
-			// Listen for an incoming connection.
-			conn, err := srv.Accept()
-			if err != nil {
-				log.Errorln(err.Error())
-			}
-			// Handle connections in a new goroutine.
+		acceptConnections(ctx, srv, listener, func(conn net.Conn) {
 			switch c {
 			case config.TCPTelnet:
-				go handleTelnetRequest(conn, cp437ToUtf8)
+				handleTelnetRequest(conn, listener, tlsConfig)
+			case config.TCPTelnets:
+				handleTelnetsRequest(conn, listener, tlsConfig)
 			case config.TCPRaw:
-				go handleRawRequest(conn, cp437ToUtf8)
+				handleRawRequest(conn, cp437ToUtf8)
+			case config.TCPRLogin:
+				handleRLoginRequest(conn, listener)
 			}
-
-		}
+		})
 	} else {
-		// Ssh is more complicated. We disable authentication, as this is handled within the game.
-		// We also configure the private key here.
+		// Ssh is more complicated. Authentication uses the accounts of the game, see sshauth.go.
+		// We also configure the host keys here, missing keys are generated.
 		// This adapted from the example here: https://godoc.org/golang.org/x/crypto/ssh#example-NewServerConn
 		// This works totally different from the telnet/raw implementation.
 
 		sshConfig := &ssh.ServerConfig{
-			NoClientAuth: true,
+			PasswordCallback:            sshPasswordCallback,
+			PublicKeyCallback:           sshPublicKeyCallback,
+			KeyboardInteractiveCallback: sshKeyboardInteractiveCallback,
 		}
-		privateBytes, err := ioutil.ReadFile(config.AppOptions.SSHPrivateKey)
+		hostKeys, err := hostkey.Load(config.AppOptions.SSHHostKeyDir)
 		if err != nil {
-			log.Fatalln("Failed to load private key:", err)
+			log.Errorf("Failed to load ssh host keys, %s listener on address %s is disabled: %s", c, address, err.Error())
+			return
 		}
-		privateKey, err := ssh.ParsePrivateKey(privateBytes)
-		if err != nil {
-			log.Fatalln("Failed to parse private key:", err)
+		for _, hostKey := range hostKeys {
+			sshConfig.AddHostKey(hostKey)
+		}
+		// a configured key replaces the generated key with the same algorithm
+		if config.AppOptions.SSHPrivateKey != "" {
+			hostKey, err := hostkey.LoadFile(config.AppOptions.SSHPrivateKey)
+			if err != nil {
+				log.Warnf("Ignoring ssh private key: %s", err.Error())
+			} else {
+				sshConfig.AddHostKey(hostKey)
+			}
 		}
-		sshConfig.AddHostKey(privateKey)
 
 		// start our actual listener
-		srv, err := net.Listen("tcp", address)
+		srv, err := listen(listener, address)
 		if err != nil {
 			log.Fatal(err.Error())
 		}
-		// close listener on exit
-		defer func() {
-			err := srv.Close()
-			if err != nil {
-				log.Errorln(err.Error())
-			}
-			wg.Done()
-		}()
 
 		// start accepting connections
 		log.Infof("Started %s listener successfully on address %s.", c, address)
+		acceptConnections(ctx, srv, listener, func(conn net.Conn) {
+			handleSSHRequest(conn, sshConfig, listener)
+		})
+	}
+}
 
-		for {
-			// Listen for an incoming connection.
-			conn, err := srv.Accept()
-			if err != nil {
+// Accepts connections until ctx is done. The listener is closed when this returns.
+func acceptConnections(ctx context.Context, srv net.Listener, listener config.Listener, handle func(net.Conn)) {
+	limiter := newConnectionLimiter(listener)
+	// closing the listener is the only way to interrupt Accept
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-stopped:
+		}
+		err := srv.Close()
+		if err != nil && ctx.Err() == nil {
+			log.Errorln(err.Error())
+		}
+	}()
+
+	for {
+		// Listen for an incoming connection.
+		conn, err := srv.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				log.Infof("Stopped %s listener on address %s.", listener.ListenType, srv.Addr())
+				return
+			}
+			// eg: too many open files. Wait a bit, connections might be closed in the meantime.
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
 				log.Errorln(err.Error())
+				time.Sleep(acceptRetryDelay)
+				continue
 			}
+			log.Errorf("Stopped %s listener on address %s: %s", listener.ListenType, srv.Addr(), err.Error())
+			return
+		}
+		// Handle connections in a new goroutine.
+		go admit(conn, listener, limiter, handle)
+	}
+}
 
-			go handleSSHRequest(conn, sshConfig, cp437ToUtf8)
+// Closes connections from banned addresses and connections over the limits, and hands the others to the handler.
+// Behind a load balancer, RemoteAddr waits for the PROXY protocol header, so this doesn't run in the accept loop.
+func admit(conn net.Conn, listener config.Listener, limiter *connectionLimiter, handle func(net.Conn)) {
+	if guard.IsBanned(conn.RemoteAddr().String()) {
+		log.Infof("%s - Rejected connection, address is banned", conn.RemoteAddr())
+		monitoring.RejectedConnections.WithLabelValues("banned").Inc()
+		conn.Close()
+		return
+	}
+	reason, ok := limiter.acquire(conn.RemoteAddr())
+	if !ok {
+		log.Infof("%s - Rejected connection, limit reached: %s", conn.RemoteAddr(), reason)
+		monitoring.RejectedConnections.WithLabelValues(reason).Inc()
+		// the message would only confuse the TLS handshake
+		if listener.ListenType != config.TCPTelnets {
+			_ = conn.SetWriteDeadline(time.Now().Add(refusalWriteTimeout))
+			_, _ = conn.Write([]byte(refusalMessages[reason]))
 		}
+		conn.Close()
+		return
 	}
+	defer limiter.release(conn.RemoteAddr())
+	handle(conn)
 }
 
-// ssh connection handling
+// Shutdown tells all players the server is going down, and gives them the drain period to finish what they're doing.
+// Sessions that are still running after that are saved and hung up. Call this after the listeners have stopped.
+func Shutdown(drain time.Duration) {
+	if session.ActiveSessions() > 0 && drain > 0 {
+		log.Infof("Waiting %s for %d session(s) to finish...", drain, session.ActiveSessions())
+		session.Broadcast(fmt.Sprintf(shutdownMessage, int(drain.Seconds())))
+		session.Wait(drain)
+	}
+	if session.ActiveSessions() > 0 {
+		// save first, hanging up can take a while
+		log.Infof("Saving and disconnecting %d session(s)...", session.ActiveSessions())
+		session.SaveAll()
+		session.BroadcastNow(shutdownNowMessage)
+	}
+	stopSessions()
+	if !session.Wait(hangupTimeout) {
+		log.Errorf("%d session(s) didn't stop in time", session.ActiveSessions())
+	}
+}
 
-func parseSize(data []byte) (w uint32, h uint32) {
-	w = binary.BigEndian.Uint32(data)
-	h = binary.BigEndian.Uint32(data[4:])
-	return
+// opens the socket of a listener. Behind a load balancer, every connection starts with a PROXY protocol header.
+func listen(listener config.Listener, address string) (net.Listener, error) {
+	srv, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	if listener.ProxyProtocol {
+		return proxyproto.NewListener(srv, proxyHeaderTimeout), nil
+	}
+	return srv, nil
 }
 
-func handleSSHRequest(conn net.Conn, conf *ssh.ServerConfig, cp437ToUtf8 bool) {
+// ssh connection handling
+
+func handleSSHRequest(conn net.Conn, conf *ssh.ServerConfig, listener config.Listener) {
 	log.Infof("%s - Connected", conn.RemoteAddr())
-	_, chans, reqs, err := ssh.NewServerConn(conn, conf)
+	// don't let clients keep the connection open without authenticating
+	err := conn.SetDeadline(time.Now().Add(listener.SSH.HandshakeTimeout))
 	if err != nil {
-		log.Errorf("%s - ssh handshake failed", conn.RemoteAddr())
-	} else {
-		log.Tracef("%s - ssh handshake successful", conn.RemoteAddr())
+		log.Errorln(err.Error())
+	}
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, conf)
+	if err != nil {
+		// the connection has been closed already
+		log.Errorf("%s - ssh handshake failed: %s", conn.RemoteAddr(), err.Error())
+		return
+	}
+	log.Tracef("%s - ssh handshake successful", conn.RemoteAddr())
+	err = conn.SetDeadline(time.Time{})
+	if err != nil {
+		log.Errorln(err.Error())
 	}
 
-	go ssh.DiscardRequests(reqs)
+	currentConn := newSSHConnection(sshConn, listener.SSH.MaxChannels)
+	defer close(currentConn.done)
+	go currentConn.handleGlobalRequests(reqs)
+	if listener.Keepalive.Enabled {
+		go currentConn.keepalive(listener.Keepalive.Interval, listener.Keepalive.Timeout)
+	}
 
 	for newChannel := range chans {
 		if newChannel.ChannelType() != "session" {
-			err = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			currentConn.rejectChannel(newChannel)
+			continue
+		}
+		if !currentConn.openChannel() {
+			log.Infof("%s - Rejected channel, limit of %d channels reached", conn.RemoteAddr(), listener.SSH.MaxChannels)
+			err = newChannel.Reject(ssh.ResourceShortage, "too many channels")
 			if err != nil {
 				log.Errorln(err.Error())
 			}
@@ -179,66 +292,124 @@ func handleSSHRequest(conn net.Conn, conf *ssh.ServerConfig, cp437ToUtf8 bool) {
 		channel, requests, err := newChannel.Accept()
 		if err != nil {
 			log.Errorln(err.Error())
+			currentConn.closeChannel()
+			continue
 		}
 
 		term := ansiterm.CreateAnsiTerminal(channel)
-		term.Cp437toUtf8 = cp437ToUtf8
+		term.SetCp437toUtf8(listener.ConvertUTF8)
 		currentSession := session.CreateSession(term, config.TCPSSH, conn.RemoteAddr().String())
+		// no need to login again in the game
+		currentSession.User = authenticatedSSHUser(sshConn.Permissions)
 
 		// Sessions have out-of-band requests such as "shell",
-		// "pty-req" and "env".  Here we handle only the
-		// "shell" request.
-		go func(in <-chan *ssh.Request) {
-			for req := range in {
-				ok := false
-				switch req.Type {
-				case "pty-req":
-					termLength := int(req.Payload[3]) // this is very naive, as the actual length is encoded as vlint32: http://lists.w3.org/Archives/Public/ietf-tls/msg02555.html
-					w, h := parseSize(req.Payload[termLength+4 : termLength+12])
-					log.Debugf("%s - receive pty-request for terminal size w:%d h:%d", conn.RemoteAddr(), w, h)
-					term.ResizeTerminal(int(w), int(h))
-					ok = true
-				case "window-change":
-					w, h := parseSize(req.Payload[:8])
-					log.Debugf("%s - receive window-change for terminal size w:%d h:%d", conn.RemoteAddr(), w, h)
-					term.ResizeTerminal(int(w), int(h))
-				case "shell":
-					ok = true
-
-				}
-				if req.WantReply {
-					err = req.Reply(ok, nil)
-					if err != nil {
-						log.Errorln(err.Error())
-					}
+		// "pty-req" and "env". We only start the game when the client asks for a shell.
+		shell := make(chan bool, 1)
+		go handleSSHChannelRequests(currentConn, channel, requests, currentSession, shell)
+		// every channel runs on its own, so one channel doesn't block the others
+		go func() {
+			defer currentConn.closeChannel()
+			if !<-shell {
+				// the client may have closed the channel already
+				err := channel.Close()
+				if err != nil && err != io.EOF {
+					log.Errorln(err.Error())
 				}
+				return
 			}
-		}(requests)
-
-		// start actual session
-		session.Start(currentSession, hangupChannel)
-
+			// start actual session
+			session.Start(sessionContext, currentSession)
+			currentConn.releaseInteractive()
+		}()
 	}
 }
 
 // telnet connection handling
 
-func handleTelnetRequest(conn net.Conn, cp437ToUtf8 bool) {
-	// Make a buffer to hold incoming data.
-	buf := make([]byte, 1024)
+// telnet over TLS. The handshake is done first, after that it's a normal telnet connection.
+func handleTelnetsRequest(conn net.Conn, listener config.Listener, tlsConfig *tls.Config) {
+	tlsConn := tls.Server(conn, tlsConfig)
+	err := tlsConn.SetDeadline(time.Now().Add(tlsHandshakeTimeout))
+	if err == nil {
+		err = tlsConn.Handshake()
+	}
+	if err == nil {
+		err = tlsConn.SetDeadline(time.Time{})
+	}
+	if err != nil {
+		log.Errorf("%s - TLS handshake failed: %s", conn.RemoteAddr(), err.Error())
+		err = conn.Close()
+		if err != nil {
+			log.Errorln(err.Error())
+		}
+		return
+	}
+	handleTelnetRequest(tlsConn, listener, nil)
+}
+
+func handleTelnetRequest(conn net.Conn, listener config.Listener, tlsConfig *tls.Config) {
 	telnetConn := telnet.NewConnection(conn)
 	log.Infof("%s - Connected", telnetConn.RemoteAddr())
+	// encrypt the connection before anything else is negotiated
+	if listener.StartTLS && tlsConfig != nil {
+		encrypted, err := telnetConn.StartTLS(tlsConfig, tlsHandshakeTimeout)
+		if err != nil {
+			log.Errorf("%s - START_TLS failed: %s", telnetConn.RemoteAddr(), err.Error())
+			err = telnetConn.Close()
+			if err != nil {
+				log.Errorln(err.Error())
+			}
+			return
+		}
+		log.Debugf("%s - Connection encrypted: %t", telnetConn.RemoteAddr(), encrypted)
+	}
 	term := ansiterm.CreateAnsiTerminal(telnetConn)
-	term.Cp437toUtf8 = cp437ToUtf8
-	currentSession := session.CreateSession(term, config.TCPTelnet, conn.RemoteAddr().String())
+	term.SetCp437toUtf8(listener.ConvertUTF8)
+	currentSession := session.CreateSession(term, listener.ListenType, conn.RemoteAddr().String())
+	currentSession.OutOfBand = telnetConn
+	telnetConn.InstallInterruptHandler(currentSession.Interrupt)
 	telnetConn.InstallResizeHandler(term.ResizeTerminal)
+	telnetConn.InstallTermTypeHandler(func(names []string, mtts int) {
+		term.SetTerminalType(names, ansiterm.Capability(mtts))
+	})
+	// the negotiated character set overrides the setting of the listener. A UTF-8 LANG is only used when there's no
+	// negotiated character set.
+	telnetConn.InstallCharsetHandler(func(charset string) {
+		term.SetCp437toUtf8(charset == "UTF-8")
+	})
+	telnetConn.InstallEnvironHandler(func(env map[string]string) {
+		currentSession.SetEnvironment(env)
+		if telnetConn.Charset() == "" {
+			if lang, ok := env["LANG"]; ok {
+				upperLang := strings.ToUpper(lang)
+				if strings.HasSuffix(upperLang, "UTF-8") || strings.HasSuffix(upperLang, "UTF8") {
+					term.SetCp437toUtf8(true)
+				}
+			}
+		}
+	})
 	telnetConn.RequestTermSize()
+	telnetConn.RequestTermType()
+	telnetConn.RequestCompression()
+	telnetConn.RequestOutOfBand()
+	telnetConn.RequestEnvironment()
+	telnetConn.RequestCharset()
 	log.Traceln(term)
 
-	// Read a bit of data to let the telnet negotiation finish. Ignore any actual data for now.
-	_, _ = telnetConn.Read(buf)
+	// Let the telnet negotiation finish. Ignore any actual data for now.
+	telnetConn.Negotiate(telnetNegotiationTimeout)
+
+	// hang up half-open connections
+	if listener.Keepalive.Enabled {
+		keepalive := listener.Keepalive
+		telnetConn.StartKeepalive(keepalive.Interval, keepalive.Timeout, keepalive.UseNOP, func() {
+			// the peer is gone, make sure pending reads and writes fail immediately so the hangup doesn't block.
+			_ = conn.SetDeadline(time.Now())
+			currentSession.Hangup()
+		})
+	}
 
-	session.Start(currentSession, hangupChannel)
+	session.Start(sessionContext, currentSession)
 }
 
 // Raw TCP connection handling
@@ -246,8 +417,8 @@ func handleTelnetRequest(conn net.Conn, cp437ToUtf8 bool) {
 func handleRawRequest(conn net.Conn, cp437ToUtf8 bool) {
 	log.Infof("%s - Connected", conn.RemoteAddr())
 	term := ansiterm.CreateAnsiTerminal(conn)
-	term.Cp437toUtf8 = cp437ToUtf8
+	term.SetCp437toUtf8(cp437ToUtf8)
 	currentSession := session.CreateSession(term, config.TCPRaw, conn.RemoteAddr().String())
 
-	session.Start(currentSession, hangupChannel)
+	session.Start(sessionContext, currentSession)
 }
@@ -0,0 +1,253 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package termserve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jeroenjacobs79/tobw/internal/ansiterm"
+	"github.com/jeroenjacobs79/tobw/internal/config"
+	"github.com/jeroenjacobs79/tobw/internal/guard"
+	"github.com/jeroenjacobs79/tobw/internal/monitoring"
+	"github.com/jeroenjacobs79/tobw/internal/session"
+	"github.com/jeroenjacobs79/tobw/internal/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+// The websocket listener serves a web page with a terminal emulator, for players without a telnet or ssh client.
+// The page connects back to /ws. Binary messages carry the terminal data, text messages are JSON control messages.
+// The terminal emulator (xterm.js) is part of the binary, see the static directory.
+
+//go:generate go run static_generate.go
+
+const (
+	// slow clients can't keep connections open by sending their request headers very slowly
+	webReadHeaderTimeout = 10 * time.Second
+	// keep-alive connections that don't send another request are closed after this time
+	webIdleTimeout = 2 * time.Minute
+	// the static files don't change while the server is running
+	staticMaxAge = 24 * time.Hour
+)
+
+// control message sent by the browser
+type webTerminalMessage struct {
+	Type string `json:"type"`
+	Cols int    `json:"cols"`
+	Rows int    `json:"rows"`
+}
+
+func startWebSocketListener(ctx context.Context, listener config.Listener, address string) {
+	// don't use the default mux, the Prometheus endpoint lives there
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", serveWebTerminal)
+	mux.HandleFunc("/static/", serveStaticFile)
+	limiter := newConnectionLimiter(listener)
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		handleWebSocketRequest(w, r, listener, limiter)
+	})
+
+	srv, err := listen(listener, address)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	// ReadTimeout and WriteTimeout would also apply to the websocket connections, so they aren't set
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: webReadHeaderTimeout,
+		IdleTimeout:       webIdleTimeout,
+	}
+	// websocket connections are hijacked, closing the server doesn't affect running sessions
+	go func() {
+		<-ctx.Done()
+		err := server.Close()
+		if err != nil {
+			log.Errorln(err.Error())
+		}
+	}()
+	log.Infof("Started %s listener successfully on address %s.", listener.ListenType, address)
+	err = server.Serve(srv)
+	if err != nil && err != http.ErrServerClosed {
+		log.Errorln(err.Error())
+		return
+	}
+	log.Infof("Stopped %s listener on address %s.", listener.ListenType, address)
+}
+
+func serveWebTerminal(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, err := w.Write([]byte(webTerminalPage))
+	if err != nil {
+		log.Errorln(err.Error())
+	}
+}
+
+func serveStaticFile(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/static/")
+	content, ok := staticFiles[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(staticMaxAge.Seconds())))
+	// the content type is based on the extension of the name
+	http.ServeContent(w, r, name, time.Time{}, strings.NewReader(content))
+}
+
+// browsers send the page that opened the connection in the Origin header. Only our own page can connect, so other
+// web sites can't use the session of a player (Cross-Site WebSocket Hijacking). Clients that aren't browsers don't
+// send an Origin.
+func allowedOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	// reverse proxies can change the Host header, browsers can't set X-Forwarded-Host
+	for _, host := range []string{r.Host, r.Header.Get("X-Forwarded-Host")} {
+		if host != "" && strings.EqualFold(originURL.Host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// websocket connection handling
+
+func handleWebSocketRequest(w http.ResponseWriter, r *http.Request, listener config.Listener, limiter *connectionLimiter) {
+	if guard.IsBanned(r.RemoteAddr) {
+		log.Infof("%s - Rejected connection, address is banned", r.RemoteAddr)
+		monitoring.RejectedConnections.WithLabelValues("banned").Inc()
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	remoteAddr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr)
+	if err != nil {
+		log.Errorln(err.Error())
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	reason, ok := limiter.acquire(remoteAddr)
+	if !ok {
+		log.Infof("%s - Rejected connection, limit reached: %s", r.RemoteAddr, reason)
+		monitoring.RejectedConnections.WithLabelValues(reason).Inc()
+		http.Error(w, refusalMessages[reason], http.StatusTooManyRequests)
+		return
+	}
+	defer limiter.release(remoteAddr)
+	if !allowedOrigin(r) {
+		log.Infof("%s - Rejected websocket connection from origin %s", r.RemoteAddr, r.Header.Get("Origin"))
+		monitoring.RejectedConnections.WithLabelValues("origin").Inc()
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		log.Debugf("%s - Websocket upgrade failed: %s", r.RemoteAddr, err.Error())
+		return
+	}
+	log.Infof("%s - Connected", conn.RemoteAddr())
+	term := ansiterm.CreateAnsiTerminal(conn)
+	term.SetCp437toUtf8(listener.ConvertUTF8)
+	// the browser always runs xterm.js
+	term.SetTerminalType([]string{"xterm-256color"}, 0)
+	currentSession := session.CreateSession(term, config.TCPWebSocket, conn.RemoteAddr().String())
+	conn.InstallTextHandler(func(data []byte) {
+		var msg webTerminalMessage
+		err := json.Unmarshal(data, &msg)
+		if err != nil {
+			log.Debugf("%s - Invalid websocket control message: %s", conn.RemoteAddr(), err.Error())
+			return
+		}
+		switch msg.Type {
+		case "resize":
+			log.Debugf("%s - receive resize for terminal size w:%d h:%d", conn.RemoteAddr(), msg.Cols, msg.Rows)
+			if msg.Cols > 0 && msg.Rows > 0 {
+				term.ResizeTerminal(msg.Cols, msg.Rows)
+			}
+		default:
+			log.Debugf("%s - Unsupported websocket control message: %s", conn.RemoteAddr(), msg.Type)
+		}
+	})
+
+	session.Start(sessionContext, currentSession)
+}
+
+const webTerminalPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Tale of the Black Wyvern</title>
+<link rel="stylesheet" href="static/xterm.css">
+<script src="static/xterm.js"></script>
+<script src="static/xterm-addon-fit.js"></script>
+<style>
+html, body { height: 100%; margin: 0; background: #000; }
+#terminal { height: 100%; }
+</style>
+</head>
+<body>
+<div id="terminal"></div>
+<script>
+(function () {
+  var term = new Terminal({ cursorBlink: true, fontFamily: "monospace" });
+  var fit = new FitAddon.FitAddon();
+  term.loadAddon(fit);
+  term.open(document.getElementById("terminal"));
+  fit.fit();
+  term.focus();
+
+  var scheme = location.protocol === "https:" ? "wss://" : "ws://";
+  var path = location.pathname.replace(/[^\/]*$/, "");
+  var ws = new WebSocket(scheme + location.host + path + "ws");
+  ws.binaryType = "arraybuffer";
+  var encoder = new TextEncoder();
+
+  function sendSize() {
+    if (ws.readyState === WebSocket.OPEN) {
+      ws.send(JSON.stringify({ type: "resize", cols: term.cols, rows: term.rows }));
+    }
+  }
+
+  ws.onopen = sendSize;
+  ws.onmessage = function (e) { term.write(new Uint8Array(e.data)); };
+  ws.onclose = function () { term.write("\r\n\r\n[Connection closed]\r\n"); };
+  term.onData(function (data) {
+    if (ws.readyState === WebSocket.OPEN) {
+      ws.send(encoder.encode(data));
+    }
+  });
+  term.onResize(sendSize);
+  window.addEventListener("resize", function () { fit.fit(); });
+})();
+</script>
+</body>
+</html>
+`
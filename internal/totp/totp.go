@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Time-based one-time passwords (RFC 6238), with the defaults every authenticator app supports: HMAC-SHA1, 6 digits
+// and a new code every 30 seconds.
+
+const (
+	Digits = 6
+	Period = 30 * time.Second
+	// number of periods a code can be early or late, for clients with a clock that isn't exactly right
+	Skew = 1
+	// 160 bits, as recommended by RFC 4226
+	secretLength = 20
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random secret, base32-encoded.
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretLength)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// KeyURI returns the otpauth:// URI that authenticator apps read from a QR code.
+// See: https://github.com/google/google-authenticator/wiki/Key-Uri-Format
+func KeyURI(issuer string, account string, secret string) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	return "otpauth://totp/" + label + "?" + strings.Replace(query.Encode(), "+", "%20", -1)
+}
+
+// step returns the number of periods since the unix epoch.
+func step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// code calculates the code for a period (RFC 4226 section 5.3).
+func code(key []byte, counter int64) string {
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulo := uint32(1)
+	for i := 0; i < Digits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, value%modulo)
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	return encoding.DecodeString(strings.ToUpper(strings.Replace(secret, " ", "", -1)))
+}
+
+// Code returns the code for the secret at time t.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return code(key, step(t)), nil
+}
+
+// Validate checks a code against the current period, and the periods next to it. Codes of periods up to lastStep
+// were already used, so they're rejected. On success, it returns the period of the code, to be used as lastStep next
+// time.
+func Validate(secret string, input string, t time.Time, lastStep int64) (int64, bool) {
+	key, err := decodeSecret(secret)
+	if err != nil || len(input) != Digits {
+		return 0, false
+	}
+	current := step(t)
+	for counter := current - Skew; counter <= current+Skew; counter++ {
+		if counter <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(code(key, counter)), []byte(input)) == 1 {
+			return counter, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// the SHA1 seed of RFC 6238, base32-encoded
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// test vectors from RFC 6238 appendix B, the last 6 of the 8 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, test := range tests {
+		got, err := Code(rfcSecret, time.Unix(test.unix, 0))
+		if err != nil {
+			t.Fatalf("%d: %s", test.unix, err)
+		}
+		if got != test.want {
+			t.Errorf("%d: code %s, want %s", test.unix, got, test.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := step(now)
+	codeAt := func(offset int64) string {
+		c, err := Code(rfcSecret, now.Add(time.Duration(offset)*Period))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	tests := []struct {
+		name     string
+		secret   string
+		input    string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current period", rfcSecret, codeAt(0), 0, current, true},
+		{"previous period", rfcSecret, codeAt(-1), 0, current - 1, true},
+		{"next period", rfcSecret, codeAt(1), 0, current + 1, true},
+		{"too old", rfcSecret, codeAt(-2), 0, 0, false},
+		{"too new", rfcSecret, codeAt(2), 0, 0, false},
+		{"already used", rfcSecret, codeAt(0), current, 0, false},
+		{"older than the last used code", rfcSecret, codeAt(-1), current, 0, false},
+		{"newer than the last used code", rfcSecret, codeAt(1), current, current + 1, true},
+		{"secret with spaces and lowercase", "gezd gnbv gy3t qojq gezd gnbv gy3t qojq", codeAt(0), 0, current, true},
+		{"wrong code", rfcSecret, "000000", 0, 0, false},
+		{"too short", rfcSecret, codeAt(0)[:Digits-1], 0, 0, false},
+		{"invalid secret", "not base32!", codeAt(0), 0, 0, false},
+	}
+	for _, test := range tests {
+		gotStep, gotOK := Validate(test.secret, test.input, now, test.lastStep)
+		if gotOK != test.wantOK || gotStep != test.wantStep {
+			t.Errorf("%s: got (%d, %t), want (%d, %t)", test.name, gotStep, gotOK, test.wantStep, test.wantOK)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package user
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileRepository keeps the users in a JSON file. Meant for development and small installations, every change
+// rewrites the whole file.
+type FileRepository struct {
+	path  string
+	lock  sync.RWMutex
+	users map[string]fileUser
+}
+
+// how a user is stored in the file
+type fileUser struct {
+	Username       string   `json:"username"`
+	Handle         string   `json:"handle"`
+	Email          string   `json:"email,omitempty"`
+	EmailVerified  bool     `json:"emailVerified,omitempty"`
+	PasswordHash   string   `json:"passwordHash"`
+	AuthorizedKeys []string `json:"authorizedKeys,omitempty"`
+	TOTPSecret     string   `json:"totpSecret,omitempty"`
+	TOTPLastStep   int64    `json:"totpLastStep,omitempty"`
+	RecoveryCodes  []string `json:"recoveryCodes,omitempty"`
+	// a pointer, so it's left out for users that were never online
+	LastSeen *time.Time `json:"lastSeen,omitempty"`
+}
+
+type userFile struct {
+	Users []fileUser `json:"users"`
+}
+
+// OpenFileRepository loads the users from the file. The file is created when the first user is added.
+func OpenFileRepository(path string) (*FileRepository, error) {
+	r := &FileRepository{
+		path:  path,
+		users: make(map[string]fileUser),
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var content userFile
+	err = json.Unmarshal(data, &content)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range content.Users {
+		// files from older versions don't have handles
+		if u.Handle == "" {
+			u.Handle = u.Username
+		}
+		r.users[normalizeUsername(u.Username)] = u
+	}
+	return r, nil
+}
+
+func (r *FileRepository) FindUser(username string) (*User, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	stored, ok := r.users[normalizeUsername(username)]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return stored.user(), nil
+}
+
+func (r *FileRepository) FindUserByHandle(handle string) (*User, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	stored, ok := r.findHandle(handle)
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return stored.user(), nil
+}
+
+// call with the lock held
+func (r *FileRepository) findHandle(handle string) (fileUser, bool) {
+	for _, stored := range r.users {
+		if normalizeUsername(stored.Handle) == normalizeUsername(handle) {
+			return stored, true
+		}
+	}
+	return fileUser{}, false
+}
+
+func (r *FileRepository) CreateUser(user *User) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	key := normalizeUsername(user.Username)
+	if _, exists := r.users[key]; exists {
+		return ErrUserExists
+	}
+	if _, exists := r.findHandle(user.Handle); exists {
+		return ErrHandleExists
+	}
+	r.users[key] = newFileUser(user)
+	err := r.save()
+	if err != nil {
+		delete(r.users, key)
+	}
+	return err
+}
+
+func (r *FileRepository) AddAuthorizedKey(username string, key string) error {
+	_, err := r.update(username, func(stored *fileUser) bool {
+		for _, existing := range stored.AuthorizedKeys {
+			if existing == key {
+				return false
+			}
+		}
+		stored.AuthorizedKeys = append(stored.AuthorizedKeys, key)
+		return true
+	})
+	return err
+}
+
+func (r *FileRepository) ReplacePasswordHash(username string, oldHash string, newHash string) (bool, error) {
+	return r.update(username, func(stored *fileUser) bool {
+		if stored.PasswordHash != oldHash {
+			return false
+		}
+		stored.PasswordHash = newHash
+		return true
+	})
+}
+
+func (r *FileRepository) SetLastSeen(username string, lastSeen time.Time) error {
+	_, err := r.update(username, func(stored *fileUser) bool {
+		stored.LastSeen = &lastSeen
+		return true
+	})
+	return err
+}
+
+func (r *FileRepository) SetTOTP(username string, secret string, lastStep int64, recoveryCodes []string) error {
+	_, err := r.update(username, func(stored *fileUser) bool {
+		stored.TOTPSecret = secret
+		stored.TOTPLastStep = lastStep
+		stored.RecoveryCodes = append([]string(nil), recoveryCodes...)
+		return true
+	})
+	return err
+}
+
+func (r *FileRepository) SetRecoveryCodes(username string, recoveryCodes []string) error {
+	_, err := r.update(username, func(stored *fileUser) bool {
+		stored.RecoveryCodes = append([]string(nil), recoveryCodes...)
+		return true
+	})
+	return err
+}
+
+func (r *FileRepository) UseTOTPStep(username string, secret string, step int64) (bool, error) {
+	return r.update(username, func(stored *fileUser) bool {
+		if stored.TOTPSecret == "" || stored.TOTPSecret != secret || stored.TOTPLastStep >= step {
+			return false
+		}
+		stored.TOTPLastStep = step
+		return true
+	})
+}
+
+func (r *FileRepository) UseRecoveryCode(username string, recoveryCode string) (bool, error) {
+	return r.update(username, func(stored *fileUser) bool {
+		for i, existing := range stored.RecoveryCodes {
+			if existing == recoveryCode {
+				stored.RecoveryCodes = append(stored.RecoveryCodes[:i:i], stored.RecoveryCodes[i+1:]...)
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// changes a single user, and writes the file if the change function returns true. Slices in the stored user must be
+// replaced instead of modified, so the old version can be restored if the file can't be written.
+func (r *FileRepository) update(username string, change func(stored *fileUser) bool) (changed bool, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	key := normalizeUsername(username)
+	previous, exists := r.users[key]
+	if !exists {
+		return false, ErrUserNotFound
+	}
+	updated := previous
+	updated.AuthorizedKeys = append([]string(nil), previous.AuthorizedKeys...)
+	updated.RecoveryCodes = append([]string(nil), previous.RecoveryCodes...)
+	if !change(&updated) {
+		return false, nil
+	}
+	r.users[key] = updated
+	err = r.save()
+	if err != nil {
+		r.users[key] = previous
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *FileRepository) Close() error {
+	return nil
+}
+
+func newFileUser(user *User) fileUser {
+	stored := fileUser{
+		Username:       user.Username,
+		Handle:         user.Handle,
+		Email:          user.Email,
+		EmailVerified:  user.EmailVerified,
+		PasswordHash:   user.GetPasswordHash(),
+		AuthorizedKeys: user.GetAuthorizedKeys(),
+		TOTPSecret:     user.totpSecret,
+		TOTPLastStep:   user.totpLastStep,
+		RecoveryCodes:  append([]string(nil), user.recoveryCodes...),
+	}
+	if !user.LastSeen.IsZero() {
+		lastSeen := user.LastSeen
+		stored.LastSeen = &lastSeen
+	}
+	return stored
+}
+
+func (stored fileUser) user() *User {
+	u := &User{
+		Username:       stored.Username,
+		Handle:         stored.Handle,
+		Email:          stored.Email,
+		EmailVerified:  stored.EmailVerified,
+		passwordHash:   stored.PasswordHash,
+		authorizedKeys: append([]string(nil), stored.AuthorizedKeys...),
+		totpSecret:     stored.TOTPSecret,
+		totpLastStep:   stored.TOTPLastStep,
+		recoveryCodes:  append([]string(nil), stored.RecoveryCodes...),
+	}
+	if stored.LastSeen != nil {
+		u.LastSeen = *stored.LastSeen
+	}
+	return u
+}
+
+// writes all users to a temporary file, and replaces the old file with it. Call with the lock held.
+func (r *FileRepository) save() error {
+	var content userFile
+	for _, u := range r.users {
+		content.Users = append(content.Users, u)
+	}
+	sort.Slice(content.Users, func(i, j int) bool {
+		return normalizeUsername(content.Users[i].Username) < normalizeUsername(content.Users[j].Username)
+	})
+	data, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(r.path)
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+	tmpFile, err := ioutil.TempFile(dir, ".users-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	_, err = tmpFile.Write(data)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), r.path)
+}
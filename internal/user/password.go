@@ -0,0 +1,254 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package user
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/jeroenjacobs79/tobw/internal/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing. The hashes are stored in the usual encoded form, which starts with the algorithm and includes the
+// parameters and salt: "$argon2id$v=19$m=65536,t=3,p=4$salt$hash" or "$2a$12$...". New passwords are hashed
+// with the configured hasher. Hashes of other algorithms, or with other parameters, still work, and are replaced when
+// the user logs in.
+
+// Hasher hashes passwords with one algorithm.
+type Hasher interface {
+	// Hash returns the encoded hash of the password, with a new salt.
+	Hash(password string) (string, error)
+	// Handles returns true if the encoded hash was made with the algorithm of this hasher.
+	Handles(encoded string) bool
+	// Verify checks the password against the encoded hash. Outdated is true if the hash was made with other
+	// parameters than the ones of the hasher.
+	Verify(encoded string, password string) (ok bool, outdated bool)
+}
+
+var (
+	// hasher for new passwords
+	passwordHasher Hasher = NewHasher(config.PasswordConfig{
+		Algorithm:         config.PasswordArgon2id,
+		BcryptCost:        12,
+		Argon2Memory:      64 * 1024,
+		Argon2Iterations:  3,
+		Argon2Parallelism: 4,
+	})
+	// all algorithms we can verify. The parameters are read from the hash, so they don't matter here.
+	knownHashers = []Hasher{&Argon2Hasher{}, &BcryptHasher{}}
+	// hash we compare with when the user doesn't exist, see Authenticate
+	dummyHash string
+	hashLock  sync.Mutex
+	// hashing takes a lot of CPU time and memory (64 MiB with the default argon2id parameters), so we don't hash more
+	// passwords at the same time than we have processors. Other logins wait for their turn.
+	hashSlots = make(chan struct{}, runtime.GOMAXPROCS(0))
+)
+
+// NewHasher returns the hasher for the configured algorithm and parameters.
+func NewHasher(passwordConfig config.PasswordConfig) Hasher {
+	if passwordConfig.Algorithm == config.PasswordBcrypt {
+		return &BcryptHasher{Cost: passwordConfig.BcryptCost}
+	}
+	return &Argon2Hasher{
+		Memory:      passwordConfig.Argon2Memory,
+		Iterations:  passwordConfig.Argon2Iterations,
+		Parallelism: passwordConfig.Argon2Parallelism,
+	}
+}
+
+// SetHasher replaces the hasher for new passwords. Call this before users login.
+func SetHasher(h Hasher) {
+	hashLock.Lock()
+	defer hashLock.Unlock()
+	passwordHasher = h
+	// so the first login of an unknown user doesn't take longer
+	dummyHash, _ = h.Hash("not the password of anybody")
+}
+
+func currentHasher() Hasher {
+	hashLock.Lock()
+	defer hashLock.Unlock()
+	return passwordHasher
+}
+
+func acquireHashSlot() {
+	hashSlots <- struct{}{}
+}
+
+func releaseHashSlot() {
+	<-hashSlots
+}
+
+func hashPassword(password string) (string, error) {
+	acquireHashSlot()
+	defer releaseHashSlot()
+	return currentHasher().Hash(password)
+}
+
+// returns true if the password matches. Rehash is true if the hash should be replaced by one of the current hasher.
+func verifyPassword(encoded string, password string) (ok bool, rehash bool) {
+	acquireHashSlot()
+	defer releaseHashSlot()
+	h := currentHasher()
+	if h.Handles(encoded) {
+		return h.Verify(encoded, password)
+	}
+	for _, known := range knownHashers {
+		if known.Handles(encoded) {
+			ok, _ = known.Verify(encoded, password)
+			return ok, true
+		}
+	}
+	return false, false
+}
+
+// takes about as long as checking the password of an existing user
+func dummyVerify(password string) {
+	hashLock.Lock()
+	encoded := dummyHash
+	hashLock.Unlock()
+	if encoded == "" {
+		encoded, _ = hashPassword("not the password of anybody")
+		hashLock.Lock()
+		dummyHash = encoded
+		hashLock.Unlock()
+	}
+	verifyPassword(encoded, password)
+}
+
+// Authenticate returns the user if the password is correct, or nil if it isn't or the user doesn't exist. Both take
+// about as long, so the login doesn't reveal which accounts exist.
+func Authenticate(username string, password string) *User {
+	u := Find(username)
+	if u == nil {
+		dummyVerify(password)
+		return nil
+	}
+	if !u.ValidatePassword(password) {
+		return nil
+	}
+	return u
+}
+
+// bcrypt
+
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	pwHash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	return string(pwHash), err
+}
+
+func (h *BcryptHasher) Handles(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (h *BcryptHasher) Verify(encoded string, password string) (ok bool, outdated bool) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if err != nil {
+		return
+	}
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return true, err != nil || cost != h.Cost
+}
+
+// argon2id
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+var argon2Encoding = base64.RawStdEncoding
+
+type Argon2Hasher struct {
+	// in KiB
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+func (h *Argon2Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		argon2Encoding.EncodeToString(salt), argon2Encoding.EncodeToString(key)), nil
+}
+
+func (h *Argon2Hasher) Handles(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (h *Argon2Hasher) Verify(encoded string, password string) (ok bool, outdated bool) {
+	params, salt, key, err := parseArgon2Hash(encoded)
+	if err != nil {
+		return
+	}
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return
+	}
+	return true, *params != *h || len(salt) != argon2SaltLength || len(key) != argon2KeyLength
+}
+
+// $argon2id$v=19$m=65536,t=3,p=4$salt$hash
+func parseArgon2Hash(encoded string) (params *Argon2Hasher, salt []byte, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		err = fmt.Errorf("invalid argon2id hash")
+		return
+	}
+	var version int
+	_, err = fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil {
+		return
+	}
+	if version != argon2.Version {
+		err = fmt.Errorf("unsupported argon2 version %d", version)
+		return
+	}
+	params = &Argon2Hasher{}
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism)
+	if err != nil {
+		return
+	}
+	if params.Iterations == 0 || params.Parallelism == 0 {
+		err = fmt.Errorf("invalid argon2id parameters")
+		return
+	}
+	salt, err = argon2Encoding.DecodeString(parts[4])
+	if err != nil {
+		return
+	}
+	key, err = argon2Encoding.DecodeString(parts[5])
+	if err == nil && len(key) == 0 {
+		err = fmt.Errorf("invalid argon2id hash")
+	}
+	return
+}
@@ -0,0 +1,276 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package user
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// cheap parameters, so the tests are fast
+var testArgon2 = &Argon2Hasher{Memory: 64, Iterations: 1, Parallelism: 1}
+
+// replaces the hasher for new passwords, and returns a function to restore it
+func useTestHasher(h Hasher) (restore func()) {
+	hashLock.Lock()
+	defer hashLock.Unlock()
+	previousHasher, previousDummy := passwordHasher, dummyHash
+	passwordHasher, dummyHash = h, ""
+	return func() {
+		hashLock.Lock()
+		defer hashLock.Unlock()
+		passwordHasher, dummyHash = previousHasher, previousDummy
+	}
+}
+
+func TestHasherVerify(t *testing.T) {
+	tests := []struct {
+		name         string
+		hasher       Hasher
+		verifier     Hasher
+		password     string
+		wantOK       bool
+		wantOutdated bool
+	}{
+		{"argon2id", testArgon2, testArgon2, "Grail#2024x", true, false},
+		{"argon2id, wrong password", testArgon2, testArgon2, "grail#2024x", false, false},
+		{"argon2id, more memory", testArgon2, &Argon2Hasher{Memory: 128, Iterations: 1, Parallelism: 1}, "Grail#2024x", true, true},
+		{"argon2id, more iterations", testArgon2, &Argon2Hasher{Memory: 64, Iterations: 2, Parallelism: 1}, "Grail#2024x", true, true},
+		{"argon2id, more threads", testArgon2, &Argon2Hasher{Memory: 64, Iterations: 1, Parallelism: 2}, "Grail#2024x", true, true},
+		{"bcrypt", &BcryptHasher{Cost: bcrypt.MinCost}, &BcryptHasher{Cost: bcrypt.MinCost}, "Grail#2024x", true, false},
+		{"bcrypt, wrong password", &BcryptHasher{Cost: bcrypt.MinCost}, &BcryptHasher{Cost: bcrypt.MinCost}, "Grail#2024", false, false},
+		{"bcrypt, higher cost", &BcryptHasher{Cost: bcrypt.MinCost}, &BcryptHasher{Cost: bcrypt.MinCost + 1}, "Grail#2024x", true, true},
+	}
+	for _, test := range tests {
+		encoded, err := test.hasher.Hash("Grail#2024x")
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if !test.verifier.Handles(encoded) {
+			t.Errorf("%s: hasher doesn't handle its own hash %s", test.name, encoded)
+			continue
+		}
+		ok, outdated := test.verifier.Verify(encoded, test.password)
+		if ok != test.wantOK || outdated != test.wantOutdated {
+			t.Errorf("%s: got (%t, %t), want (%t, %t)", test.name, ok, outdated, test.wantOK, test.wantOutdated)
+		}
+	}
+}
+
+func TestHashesAreSalted(t *testing.T) {
+	for _, h := range []Hasher{testArgon2, &BcryptHasher{Cost: bcrypt.MinCost}} {
+		first, _ := h.Hash("Grail#2024x")
+		second, _ := h.Hash("Grail#2024x")
+		if first == second {
+			t.Errorf("%T: the same password has the same hash twice", h)
+		}
+	}
+}
+
+func TestParseArgon2Hash(t *testing.T) {
+	valid, err := testArgon2.Hash("Grail#2024x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(valid, "$")
+	salt, key := parts[4], parts[5]
+	tests := []struct {
+		name    string
+		encoded string
+		want    *Argon2Hasher
+	}{
+		{"valid", valid, testArgon2},
+		{"default parameters", "$argon2id$v=19$m=65536,t=3,p=4$" + salt + "$" + key, &Argon2Hasher{Memory: 65536, Iterations: 3, Parallelism: 4}},
+		{"argon2i", "$argon2i$v=19$m=64,t=1,p=1$" + salt + "$" + key, nil},
+		{"old version", "$argon2id$v=16$m=64,t=1,p=1$" + salt + "$" + key, nil},
+		{"no version", "$argon2id$m=64,t=1,p=1$" + salt + "$" + key, nil},
+		{"missing parameter", "$argon2id$v=19$m=64,t=1$" + salt + "$" + key, nil},
+		{"no iterations", "$argon2id$v=19$m=64,t=0,p=1$" + salt + "$" + key, nil},
+		{"no threads", "$argon2id$v=19$m=64,t=1,p=0$" + salt + "$" + key, nil},
+		{"invalid salt", "$argon2id$v=19$m=64,t=1,p=1$!!!$" + key, nil},
+		{"empty hash", "$argon2id$v=19$m=64,t=1,p=1$" + salt + "$", nil},
+		{"bcrypt", "$2a$04$abcdefghijklmnopqrstuu", nil},
+		{"empty", "", nil},
+	}
+	for _, test := range tests {
+		params, _, _, err := parseArgon2Hash(test.encoded)
+		if test.want == nil {
+			if err == nil {
+				t.Errorf("%s: no error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if *params != *test.want {
+			t.Errorf("%s: parameters %+v, want %+v", test.name, *params, *test.want)
+		}
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	defer useTestHasher(testArgon2)()
+	current, _ := testArgon2.Hash("Grail#2024x")
+	otherParameters, _ := (&Argon2Hasher{Memory: 128, Iterations: 1, Parallelism: 1}).Hash("Grail#2024x")
+	bcryptHash, _ := (&BcryptHasher{Cost: bcrypt.MinCost}).Hash("Grail#2024x")
+	tests := []struct {
+		name       string
+		encoded    string
+		password   string
+		wantOK     bool
+		wantRehash bool
+	}{
+		{"current hasher", current, "Grail#2024x", true, false},
+		{"current hasher, wrong password", current, "Grail", false, false},
+		{"other parameters", otherParameters, "Grail#2024x", true, true},
+		{"other algorithm", bcryptHash, "Grail#2024x", true, true},
+		{"other algorithm, wrong password", bcryptHash, "Grail", false, true},
+		{"unknown algorithm", "$1$salt$hash", "Grail#2024x", false, false},
+		{"no hash", "", "", false, false},
+	}
+	for _, test := range tests {
+		ok, rehash := verifyPassword(test.encoded, test.password)
+		if ok != test.wantOK || rehash != test.wantRehash {
+			t.Errorf("%s: got (%t, %t), want (%t, %t)", test.name, ok, rehash, test.wantOK, test.wantRehash)
+		}
+	}
+}
+
+// stores a password for the user, the way a password change would
+func setTestPassword(t *testing.T, username string, password string) {
+	u := Find(username)
+	oldHash := u.GetPasswordHash()
+	err := u.SetPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = repository.ReplacePasswordHash(username, oldHash, u.GetPasswordHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidatePasswordRehash(t *testing.T) {
+	_, cleanup := setupTestRepository(t, "arthur")
+	defer cleanup()
+	defer useTestHasher(&BcryptHasher{Cost: bcrypt.MinCost})()
+	setTestPassword(t, "arthur", "Grail#2024x")
+
+	// the configuration was changed, and another session changed the user after this one loaded it
+	useTestHasher(testArgon2)
+	loggingIn := Find("arthur")
+	lastSeen := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	err := SetLastSeen("arthur", lastSeen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !loggingIn.ValidatePassword("Grail#2024x") {
+		t.Fatal("valid password rejected")
+	}
+	stored := Find("arthur")
+	if !testArgon2.Handles(stored.GetPasswordHash()) {
+		t.Errorf("stored hash %s wasn't replaced", stored.GetPasswordHash())
+	}
+	if stored.GetPasswordHash() != loggingIn.GetPasswordHash() {
+		t.Error("stored hash is not the new hash")
+	}
+	if !stored.LastSeen.Equal(lastSeen) {
+		t.Errorf("rehashing overwrote the last seen time with %s", stored.LastSeen)
+	}
+	if !stored.ValidatePassword("Grail#2024x") {
+		t.Error("valid password rejected after rehashing")
+	}
+}
+
+func TestValidatePasswordChangedMeanwhile(t *testing.T) {
+	_, cleanup := setupTestRepository(t, "arthur")
+	defer cleanup()
+	defer useTestHasher(&BcryptHasher{Cost: bcrypt.MinCost})()
+	setTestPassword(t, "arthur", "Grail#2024x")
+
+	useTestHasher(testArgon2)
+	loggingIn := Find("arthur")
+	// the player changed the password in another session
+	setTestPassword(t, "arthur", "Swallow#2024x")
+
+	loggingIn.ValidatePassword("Grail#2024x")
+	stored := Find("arthur")
+	if !stored.ValidatePassword("Swallow#2024x") || stored.ValidatePassword("Grail#2024x") {
+		t.Error("rehashing replaced the new password")
+	}
+}
+
+// a hasher that counts how many hashes are made at the same time
+type countingHasher struct {
+	lock    sync.Mutex
+	running int
+	max     int
+}
+
+func (h *countingHasher) Hash(password string) (string, error) {
+	h.lock.Lock()
+	h.running++
+	if h.running > h.max {
+		h.max = h.running
+	}
+	h.lock.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	h.lock.Lock()
+	h.running--
+	h.lock.Unlock()
+	return "counted", nil
+}
+
+func (h *countingHasher) Handles(encoded string) bool {
+	return true
+}
+
+func (h *countingHasher) Verify(encoded string, password string) (ok bool, outdated bool) {
+	h.Hash(password)
+	return true, false
+}
+
+func TestConcurrentHashing(t *testing.T) {
+	h := &countingHasher{}
+	defer useTestHasher(h)()
+	var wg sync.WaitGroup
+	for i := 0; i < 4*runtime.GOMAXPROCS(0); i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			hashPassword("Grail#2024x")
+		}()
+		go func() {
+			defer wg.Done()
+			verifyPassword("counted", "Grail#2024x")
+		}()
+	}
+	wg.Wait()
+	if h.max > cap(hashSlots) {
+		t.Errorf("%d hashes at the same time, want at most %d", h.max, cap(hashSlots))
+	}
+	if h.max == 0 {
+		t.Error("nothing was hashed")
+	}
+}
@@ -0,0 +1,398 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package user
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jeroenjacobs79/tobw/internal/config"
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+)
+
+// PostgresRepository stores the users in a PostgreSQL database.
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// Schema changes, in order. The index of a migration is its version, so never change or remove a migration that has
+// been released: add a new one.
+var migrations = []string{
+	// 1: user accounts
+	`CREATE TABLE users (
+		id            SERIAL PRIMARY KEY,
+		username      TEXT NOT NULL,
+		email         TEXT NOT NULL DEFAULT '',
+		password_hash TEXT NOT NULL,
+		created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+	);
+	CREATE UNIQUE INDEX users_username_key ON users (lower(username));`,
+	// 2: public keys for ssh logins
+	`CREATE TABLE authorized_keys (
+		user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+		key     TEXT NOT NULL,
+		PRIMARY KEY (user_id, key)
+	);`,
+	// 3: handles and email verification
+	`ALTER TABLE users ADD COLUMN handle TEXT;
+	UPDATE users SET handle = username;
+	ALTER TABLE users ALTER COLUMN handle SET NOT NULL;
+	CREATE UNIQUE INDEX users_handle_key ON users (lower(handle));
+	ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT false;`,
+	// 4: two-factor authentication
+	`ALTER TABLE users ADD COLUMN totp_secret TEXT NOT NULL DEFAULT '';
+	ALTER TABLE users ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
+	CREATE TABLE recovery_codes (
+		user_id   INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+		code_hash TEXT NOT NULL,
+		PRIMARY KEY (user_id, code_hash)
+	);`,
+	// 5: last time the player was online
+	`ALTER TABLE users ADD COLUMN last_seen TIMESTAMP WITH TIME ZONE;`,
+}
+
+// OpenPostgresRepository connects to the database, and updates the schema if needed.
+func OpenPostgresRepository(dbConfig config.DatabaseConfig) (*PostgresRepository, error) {
+	db, err := sql.Open("postgres", connectionString(dbConfig))
+	if err != nil {
+		return nil, err
+	}
+	err = db.Ping()
+	if err == nil {
+		err = migrate(db)
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &PostgresRepository{db: db}, nil
+}
+
+// builds a connection string in key=value format. Values are quoted, so they can contain spaces.
+func connectionString(dbConfig config.DatabaseConfig) string {
+	quote := strings.NewReplacer(`\`, `\\`, "'", `\'`)
+	params := []string{
+		fmt.Sprintf("host='%s'", quote.Replace(dbConfig.Host)),
+		fmt.Sprintf("port=%d", dbConfig.Port),
+		fmt.Sprintf("dbname='%s'", quote.Replace(dbConfig.Database)),
+	}
+	if dbConfig.User != "" {
+		params = append(params, fmt.Sprintf("user='%s'", quote.Replace(dbConfig.User)))
+	}
+	if dbConfig.Password != "" {
+		params = append(params, fmt.Sprintf("password='%s'", quote.Replace(dbConfig.Password)))
+	}
+	if dbConfig.SSLMode != "" {
+		params = append(params, fmt.Sprintf("sslmode='%s'", quote.Replace(dbConfig.SSLMode)))
+	}
+	return strings.Join(params, " ")
+}
+
+// applies the migrations that haven't been applied yet. The table lock makes sure only one server does this, when
+// several are started at the same time.
+func migrate(db *sql.DB) (err error) {
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	_, err = tx.Exec("LOCK TABLE schema_migrations IN EXCLUSIVE MODE")
+	if err != nil {
+		return
+	}
+	var current int
+	err = tx.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current)
+	if err != nil {
+		return
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("The database schema (version %d) is newer than this program (version %d)", current, len(migrations))
+	}
+	for version := current + 1; version <= len(migrations); version++ {
+		log.Infof("Updating database schema to version %d", version)
+		_, err = tx.Exec(migrations[version-1])
+		if err != nil {
+			return fmt.Errorf("Database migration %d failed: %s", version, err.Error())
+		}
+		_, err = tx.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", version)
+		if err != nil {
+			return
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresRepository) FindUser(username string) (*User, error) {
+	return r.findUser("lower(username)", normalizeUsername(username))
+}
+
+func (r *PostgresRepository) FindUserByHandle(handle string) (*User, error) {
+	return r.findUser("lower(handle)", normalizeUsername(handle))
+}
+
+// finds a user by an indexed column
+func (r *PostgresRepository) findUser(column string, value string) (*User, error) {
+	var id int
+	var lastSeen pq.NullTime
+	u := &User{}
+	err := r.db.QueryRow("SELECT id, username, handle, email, email_verified, password_hash, totp_secret, totp_last_step, last_seen FROM users WHERE "+column+" = $1",
+		value).Scan(&id, &u.Username, &u.Handle, &u.Email, &u.EmailVerified, &u.passwordHash, &u.totpSecret, &u.totpLastStep, &lastSeen)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lastSeen.Valid {
+		u.LastSeen = lastSeen.Time
+	}
+	u.authorizedKeys, err = r.queryStrings("SELECT key FROM authorized_keys WHERE user_id = $1 ORDER BY key", id)
+	if err != nil {
+		return nil, err
+	}
+	u.recoveryCodes, err = r.queryStrings("SELECT code_hash FROM recovery_codes WHERE user_id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// runs a query that returns a single text column
+func (r *PostgresRepository) queryStrings(query string, args ...interface{}) (result []string, err error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var value string
+		err = rows.Scan(&value)
+		if err != nil {
+			return
+		}
+		result = append(result, value)
+	}
+	err = rows.Err()
+	return
+}
+
+func (r *PostgresRepository) CreateUser(user *User) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	var id int
+	err = tx.QueryRow("INSERT INTO users (username, handle, email, email_verified, password_hash, totp_secret, totp_last_step) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		user.Username, user.Handle, user.Email, user.EmailVerified, user.GetPasswordHash(), user.totpSecret, user.totpLastStep).Scan(&id)
+	if uniqueErr := uniqueViolation(err); uniqueErr != nil {
+		return uniqueErr
+	}
+	if err != nil {
+		return
+	}
+	err = insertChildren(tx, id, user)
+	if err != nil {
+		return
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresRepository) AddAuthorizedKey(username string, key string) error {
+	id, err := r.userID(username)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec("INSERT INTO authorized_keys (user_id, key) VALUES ($1, $2) ON CONFLICT DO NOTHING", id, key)
+	return err
+}
+
+func (r *PostgresRepository) ReplacePasswordHash(username string, oldHash string, newHash string) (bool, error) {
+	result, err := r.db.Exec("UPDATE users SET password_hash = $1 WHERE lower(username) = $2 AND password_hash = $3",
+		newHash, normalizeUsername(username), oldHash)
+	if err != nil {
+		return false, err
+	}
+	count, err := result.RowsAffected()
+	return count > 0, err
+}
+
+func (r *PostgresRepository) SetLastSeen(username string, lastSeen time.Time) error {
+	result, err := r.db.Exec("UPDATE users SET last_seen = $1 WHERE lower(username) = $2", lastSeen, normalizeUsername(username))
+	if err != nil {
+		return err
+	}
+	return rowsAffected(result)
+}
+
+func (r *PostgresRepository) SetTOTP(username string, secret string, lastStep int64, recoveryCodes []string) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	var id int
+	err = tx.QueryRow("UPDATE users SET totp_secret = $1, totp_last_step = $2 WHERE lower(username) = $3 RETURNING id",
+		secret, lastStep, normalizeUsername(username)).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return
+	}
+	err = replaceRecoveryCodes(tx, id, recoveryCodes)
+	if err != nil {
+		return
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresRepository) SetRecoveryCodes(username string, recoveryCodes []string) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	var id int
+	// locks the user, so two sessions can't mix their codes
+	err = tx.QueryRow("SELECT id FROM users WHERE lower(username) = $1 FOR UPDATE", normalizeUsername(username)).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return
+	}
+	err = replaceRecoveryCodes(tx, id, recoveryCodes)
+	if err != nil {
+		return
+	}
+	return tx.Commit()
+}
+
+// the condition makes this safe when two sessions use the same code at the same time: only one of them succeeds.
+func (r *PostgresRepository) UseTOTPStep(username string, secret string, step int64) (bool, error) {
+	result, err := r.db.Exec("UPDATE users SET totp_last_step = $1 WHERE lower(username) = $2 AND totp_secret <> '' AND totp_secret = $3 AND totp_last_step < $1",
+		step, normalizeUsername(username), secret)
+	if err != nil {
+		return false, err
+	}
+	count, err := result.RowsAffected()
+	return count > 0, err
+}
+
+func (r *PostgresRepository) UseRecoveryCode(username string, recoveryCode string) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM recovery_codes WHERE code_hash = $1 AND user_id = (SELECT id FROM users WHERE lower(username) = $2)",
+		recoveryCode, normalizeUsername(username))
+	if err != nil {
+		return false, err
+	}
+	count, err := result.RowsAffected()
+	return count > 0, err
+}
+
+func replaceRecoveryCodes(tx *sql.Tx, id int, recoveryCodes []string) error {
+	_, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id = $1", id)
+	if err != nil {
+		return err
+	}
+	for _, hash := range recoveryCodes {
+		_, err = tx.Exec("INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2) ON CONFLICT DO NOTHING", id, hash)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// returns ErrUserNotFound if an update didn't change anything
+func rowsAffected(result sql.Result) error {
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// returns the id of a user, or ErrUserNotFound
+func (r *PostgresRepository) userID(username string) (id int, err error) {
+	err = r.db.QueryRow("SELECT id FROM users WHERE lower(username) = $1", normalizeUsername(username)).Scan(&id)
+	if err == sql.ErrNoRows {
+		err = ErrUserNotFound
+	}
+	return
+}
+
+func (r *PostgresRepository) Close() error {
+	return r.db.Close()
+}
+
+// translates a unique_violation on the username or handle index to our own error.
+func uniqueViolation(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok || pqErr.Code != "23505" {
+		return nil
+	}
+	if pqErr.Constraint == "users_handle_key" {
+		return ErrHandleExists
+	}
+	return ErrUserExists
+}
+
+// stores the public keys and recovery codes of a user
+func insertChildren(tx *sql.Tx, id int, user *User) error {
+	for _, key := range user.GetAuthorizedKeys() {
+		_, err := tx.Exec("INSERT INTO authorized_keys (user_id, key) VALUES ($1, $2) ON CONFLICT DO NOTHING", id, key)
+		if err != nil {
+			return err
+		}
+	}
+	for _, hash := range user.recoveryCodes {
+		_, err := tx.Exec("INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2) ON CONFLICT DO NOTHING", id, hash)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
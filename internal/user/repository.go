@@ -0,0 +1,143 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package user
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jeroenjacobs79/tobw/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Repository stores the user accounts. Usernames are case-insensitive.
+type Repository interface {
+	// FindUser returns ErrUserNotFound if the user doesn't exist.
+	FindUser(username string) (*User, error)
+	// FindUserByHandle returns ErrUserNotFound if no user has this handle.
+	FindUserByHandle(handle string) (*User, error)
+	// CreateUser returns ErrUserExists or ErrHandleExists if another user has the same name or handle.
+	CreateUser(user *User) error
+	// AddAuthorizedKey adds a public key to an existing user, without changing anything else. Adding a key the user
+	// already has is not an error.
+	AddAuthorizedKey(username string, key string) error
+	// ReplacePasswordHash replaces the password hash, if it's still the old one. Returns false if the password was
+	// changed in the meantime.
+	ReplacePasswordHash(username string, oldHash string, newHash string) (bool, error)
+	// SetLastSeen stores when the player was online for the last time.
+	SetLastSeen(username string, lastSeen time.Time) error
+	// SetTOTP replaces the two-factor secret, the last used time step and the recovery code hashes. An empty secret
+	// turns off two-factor authentication.
+	SetTOTP(username string, secret string, lastStep int64, recoveryCodes []string) error
+	// SetRecoveryCodes replaces the recovery code hashes.
+	SetRecoveryCodes(username string, recoveryCodes []string) error
+	// UseTOTPStep stores the time step of a code that was used, if the secret is still the same. Returns false if this
+	// step or a later one was used already (eg: by another session), so every code works only once.
+	UseTOTPStep(username string, secret string, step int64) (bool, error)
+	// UseRecoveryCode removes a recovery code hash. Returns false if the user doesn't have it (anymore).
+	UseRecoveryCode(username string, recoveryCode string) (bool, error)
+	Close() error
+}
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists   = errors.New("user already exists")
+	ErrHandleExists = errors.New("handle already in use")
+	ErrInvalidKey   = errors.New("invalid public key")
+)
+
+// the repository used by all listeners
+var repository Repository
+
+// Open connects to the user database in the configuration.
+func Open(dbConfig config.DatabaseConfig) (err error) {
+	switch dbConfig.Type {
+	case config.DatabasePostgres:
+		repository, err = OpenPostgresRepository(dbConfig)
+	case config.DatabaseFile:
+		repository, err = OpenFileRepository(dbConfig.Path)
+	default:
+		err = fmt.Errorf("Unknown database type: %s", dbConfig.Type)
+	}
+	return
+}
+
+// Close closes the user database.
+func Close() error {
+	if repository == nil {
+		return nil
+	}
+	return repository.Close()
+}
+
+// SetRepository replaces the user database, eg: with a file repository in a temporary directory.
+func SetRepository(r Repository) {
+	repository = r
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// Register adds a new user to the database.
+func Register(user *User) error {
+	if normalizeUsername(user.Username) == "" {
+		return fmt.Errorf("Username can't be empty")
+	}
+	user.Username = strings.TrimSpace(user.Username)
+	if user.Handle == "" {
+		user.Handle = user.Username
+	}
+	err := repository.CreateUser(user)
+	switch err {
+	case ErrUserExists:
+		return fmt.Errorf("User %s already exists", user.Username)
+	case ErrHandleExists:
+		return fmt.Errorf("Handle %s is already in use", user.Handle)
+	}
+	return err
+}
+
+// Find returns the user with the given name, or nil if the user doesn't exist.
+func Find(username string) *User {
+	u, err := repository.FindUser(username)
+	if err != nil {
+		if err != ErrUserNotFound {
+			log.Errorln(err.Error())
+		}
+		return nil
+	}
+	return u
+}
+
+// FindByHandle returns the user with the given handle, or nil if no user has this handle.
+func FindByHandle(handle string) *User {
+	u, err := repository.FindUserByHandle(handle)
+	if err != nil {
+		if err != ErrUserNotFound {
+			log.Errorln(err.Error())
+		}
+		return nil
+	}
+	return u
+}
+
+// SetLastSeen stores when the player was online for the last time. Nothing else is changed.
+func SetLastSeen(username string, lastSeen time.Time) error {
+	return repository.SetLastSeen(username, lastSeen)
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jeroenjacobs79/tobw/internal/totp"
+	log "github.com/sirupsen/logrus"
+)
+
+// Two-factor authentication with TOTP codes. Players that lose their phone can login with a recovery code, every
+// recovery code works only once.
+
+const (
+	RecoveryCodeCount = 10
+	// characters in a recovery code, without the dash
+	recoveryCodeLength = 10
+	// bytes of random salt for every recovery code hash
+	recoverySaltLength = 16
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ErrInvalidCode is returned when two-factor authentication can't be enabled, because the code is wrong.
+var ErrInvalidCode = errors.New("invalid code")
+
+// TOTPEnabled returns true if the user needs a code to login.
+func (user *User) TOTPEnabled() bool {
+	return user.totpSecret != ""
+}
+
+// EnableTOTP turns on two-factor authentication, if the code shows the authenticator app of the player has the
+// secret. Returns ErrInvalidCode if it doesn't. Returns the recovery codes, they're only stored as hashes.
+func (user *User) EnableTOTP(secret string, code string) (recoveryCodes []string, err error) {
+	step, ok := totp.Validate(secret, strings.TrimSpace(code), time.Now(), 0)
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+	recoveryCodes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	// the code that was used to enable it can't be used to login
+	err = repository.SetTOTP(user.Username, secret, step, hashes)
+	if err != nil {
+		return nil, err
+	}
+	user.totpSecret = secret
+	user.totpLastStep = step
+	user.recoveryCodes = hashes
+	return
+}
+
+// DisableTOTP turns off two-factor authentication, and removes the recovery codes.
+func (user *User) DisableTOTP() error {
+	err := repository.SetTOTP(user.Username, "", 0, nil)
+	if err != nil {
+		return err
+	}
+	user.totpSecret = ""
+	user.totpLastStep = 0
+	user.recoveryCodes = nil
+	return nil
+}
+
+// NewRecoveryCodes replaces the recovery codes of the user.
+func (user *User) NewRecoveryCodes() (recoveryCodes []string, err error) {
+	recoveryCodes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = repository.SetRecoveryCodes(user.Username, hashes)
+	if err != nil {
+		return nil, err
+	}
+	user.recoveryCodes = hashes
+	return
+}
+
+// generates recovery codes, and the hashes we store
+func newRecoveryCodes() (recoveryCodes []string, hashes []string, err error) {
+	for i := 0; i < RecoveryCodeCount; i++ {
+		random := make([]byte, 8)
+		_, err = rand.Read(random)
+		if err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryEncoding.EncodeToString(random)[:recoveryCodeLength])
+		salt := make([]byte, recoverySaltLength)
+		_, err = rand.Read(salt)
+		if err != nil {
+			return nil, nil, err
+		}
+		recoveryCodes = append(recoveryCodes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		hashes = append(hashes, hashRecoveryCode(code, salt))
+	}
+	return
+}
+
+// RecoveryCodesLeft returns the number of recovery codes that weren't used yet.
+func (user *User) RecoveryCodesLeft() int {
+	return len(user.recoveryCodes)
+}
+
+// ValidateTOTP checks a code from the authenticator app. A code can only be used once, also when two sessions of the
+// same user try to use it.
+func (user *User) ValidateTOTP(code string) bool {
+	if !user.TOTPEnabled() {
+		return false
+	}
+	step, ok := totp.Validate(user.totpSecret, strings.TrimSpace(code), time.Now(), user.totpLastStep)
+	if !ok {
+		return false
+	}
+	ok, err := repository.UseTOTPStep(user.Username, user.totpSecret, step)
+	if err != nil {
+		log.Errorln(err.Error())
+		return false
+	}
+	if ok {
+		user.totpLastStep = step
+	}
+	return ok
+}
+
+// ValidateSecondFactor accepts a code from the authenticator app, or a recovery code. Used recovery codes are
+// removed.
+func (user *User) ValidateSecondFactor(code string) bool {
+	if user.ValidateTOTP(code) {
+		return true
+	}
+	if !user.TOTPEnabled() {
+		return false
+	}
+	code = normalizeRecoveryCode(code)
+	for i, hash := range user.recoveryCodes {
+		if !matchRecoveryCode(hash, code) {
+			continue
+		}
+		// fails if another session used the code first
+		ok, err := repository.UseRecoveryCode(user.Username, hash)
+		if err != nil {
+			log.Errorln(err.Error())
+			return false
+		}
+		user.recoveryCodes = append(user.recoveryCodes[:i:i], user.recoveryCodes[i+1:]...)
+		return ok
+	}
+	return false
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// hashes are stored as salt$hash, both in hex
+func hashRecoveryCode(code string, salt []byte) string {
+	sum := sha256.Sum256(append(append([]byte(nil), salt...), code...))
+	return hex.EncodeToString(salt) + "$" + hex.EncodeToString(sum[:])
+}
+
+func matchRecoveryCode(hash string, code string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 2 {
+		return false
+	}
+	salt, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashRecoveryCode(code, salt)), []byte(hash)) == 1
+}
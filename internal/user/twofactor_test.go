@@ -0,0 +1,242 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package user
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeroenjacobs79/tobw/internal/totp"
+)
+
+// uses a file repository in a temporary directory, with one user. Returns the path of the file.
+func setupTestRepository(t *testing.T, username string) (path string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "tobw-user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path = filepath.Join(dir, "users.json")
+	r, err := OpenFileRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetRepository(r)
+	err = Register(&User{Username: username})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+// enables two-factor authentication for the user, with a code of the previous period, so the current code can still
+// be used to login.
+func enableTestTOTP(t *testing.T, u *User) (secret string, recoveryCodes []string) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := totp.Code(secret, time.Now().Add(-totp.Period))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recoveryCodes, err = u.EnableTOTP(secret, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestEnableTOTP(t *testing.T) {
+	_, cleanup := setupTestRepository(t, "arthur")
+	defer cleanup()
+	u := Find("arthur")
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = u.EnableTOTP(secret, "000000x")
+	if err != ErrInvalidCode {
+		t.Fatalf("invalid code: got error %v, want %v", err, ErrInvalidCode)
+	}
+	if u.TOTPEnabled() || Find("arthur").TOTPEnabled() {
+		t.Fatal("enabled with an invalid code")
+	}
+	code, err := totp.Code(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, err := u.EnableTOTP(secret, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != RecoveryCodeCount {
+		t.Errorf("got %d recovery codes, want %d", len(codes), RecoveryCodeCount)
+	}
+	stored := Find("arthur")
+	if !stored.TOTPEnabled() || stored.RecoveryCodesLeft() != RecoveryCodeCount {
+		t.Errorf("stored user: enabled %t with %d recovery codes", stored.TOTPEnabled(), stored.RecoveryCodesLeft())
+	}
+	// the code that enabled it can't be used to login
+	if stored.ValidateSecondFactor(code) {
+		t.Error("the code used for enabling was accepted again")
+	}
+}
+
+func TestValidateTOTPReplay(t *testing.T) {
+	_, cleanup := setupTestRepository(t, "arthur")
+	defer cleanup()
+	secret, _ := enableTestTOTP(t, Find("arthur"))
+	code, err := totp.Code(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// two sessions of the same player, both loaded before the code is used
+	first := Find("arthur")
+	second := Find("arthur")
+	if !first.ValidateTOTP(code) {
+		t.Fatal("valid code rejected")
+	}
+	if first.ValidateTOTP(code) {
+		t.Error("code accepted twice by the same session")
+	}
+	if second.ValidateTOTP(code) {
+		t.Error("code accepted again by another session")
+	}
+}
+
+func TestValidateRecoveryCode(t *testing.T) {
+	_, cleanup := setupTestRepository(t, "arthur")
+	defer cleanup()
+	_, codes := enableTestTOTP(t, Find("arthur"))
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"as shown", codes[0], true},
+		{"used before", codes[0], false},
+		{"without the dash", strings.Replace(codes[1], "-", "", 1), true},
+		{"uppercase with spaces", " " + strings.ToUpper(codes[2]) + " ", true},
+		{"unknown code", "aaaaa-aaaaa", false},
+		{"empty", "", false},
+	}
+	u := Find("arthur")
+	for _, test := range tests {
+		if got := u.ValidateSecondFactor(test.input); got != test.want {
+			t.Errorf("%s: got %t, want %t", test.name, got, test.want)
+		}
+	}
+	if left := u.RecoveryCodesLeft(); left != RecoveryCodeCount-3 {
+		t.Errorf("%d recovery codes left, want %d", left, RecoveryCodeCount-3)
+	}
+	// the used codes are removed from the database too
+	if Find("arthur").RecoveryCodesLeft() != RecoveryCodeCount-3 {
+		t.Errorf("stored user has %d recovery codes left, want %d", Find("arthur").RecoveryCodesLeft(), RecoveryCodeCount-3)
+	}
+}
+
+func TestRecoveryCodeUsedByOtherSession(t *testing.T) {
+	_, cleanup := setupTestRepository(t, "arthur")
+	defer cleanup()
+	_, codes := enableTestTOTP(t, Find("arthur"))
+	first := Find("arthur")
+	second := Find("arthur")
+	if !first.ValidateSecondFactor(codes[0]) {
+		t.Fatal("valid recovery code rejected")
+	}
+	if second.ValidateSecondFactor(codes[0]) {
+		t.Error("recovery code accepted again by another session")
+	}
+}
+
+func TestRecoveryCodesAreSalted(t *testing.T) {
+	first := hashRecoveryCode("abcdefghij", []byte("salt"))
+	second := hashRecoveryCode("abcdefghij", []byte("other salt"))
+	if first == second {
+		t.Error("the same code has the same hash with another salt")
+	}
+	for _, test := range []struct {
+		hash string
+		code string
+		want bool
+	}{
+		{first, "abcdefghij", true},
+		{second, "abcdefghij", true},
+		{first, "abcdefghik", false},
+		{strings.Replace(first, "$", "", 1), "abcdefghij", false},
+		{"zz" + first, "abcdefghij", false},
+		{"", "", false},
+	} {
+		if got := matchRecoveryCode(test.hash, test.code); got != test.want {
+			t.Errorf("matchRecoveryCode(%q, %q) = %t, want %t", test.hash, test.code, got, test.want)
+		}
+	}
+}
+
+func TestTwoFactorChangesAreStored(t *testing.T) {
+	path, cleanup := setupTestRepository(t, "arthur")
+	defer cleanup()
+	u := Find("arthur")
+	_, codes := enableTestTOTP(t, u)
+	if !u.ValidateSecondFactor(codes[0]) {
+		t.Fatal("valid recovery code rejected")
+	}
+	// read the file again, to make sure the changes were written
+	reload := func() *User {
+		r, err := OpenFileRepository(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		SetRepository(r)
+		return Find("arthur")
+	}
+	if stored := reload(); stored.RecoveryCodesLeft() != RecoveryCodeCount-1 {
+		t.Errorf("after using a code: %d recovery codes stored, want %d", stored.RecoveryCodesLeft(), RecoveryCodeCount-1)
+	}
+
+	newCodes, err := u.NewRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := reload()
+	if stored.RecoveryCodesLeft() != RecoveryCodeCount {
+		t.Errorf("after new codes: %d recovery codes stored, want %d", stored.RecoveryCodesLeft(), RecoveryCodeCount)
+	}
+	if stored.ValidateSecondFactor(codes[1]) {
+		t.Error("old recovery code accepted after new codes were generated")
+	}
+	if !stored.ValidateSecondFactor(newCodes[0]) {
+		t.Error("new recovery code rejected")
+	}
+
+	err = stored.DisableTOTP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored = reload()
+	if stored.TOTPEnabled() || stored.RecoveryCodesLeft() != 0 {
+		t.Errorf("after disabling: enabled %t with %d recovery codes", stored.TOTPEnabled(), stored.RecoveryCodesLeft())
+	}
+	if stored.ValidateSecondFactor(newCodes[1]) {
+		t.Error("recovery code accepted after two-factor authentication was disabled")
+	}
+}
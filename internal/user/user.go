@@ -17,30 +17,66 @@
 package user
 
 import (
-	"golang.org/x/crypto/bcrypt"
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
 )
 
 type User struct {
-	Username     string
-	Email        string
-	passwordHash string
+	Username string
+	// the name other players see
+	Handle        string
+	Email         string
+	EmailVerified bool
+	passwordHash  string
+	// public keys that can be used to login over ssh, in authorized_keys format
+	authorizedKeys []string
+	keyLock        sync.RWMutex
+	// two-factor authentication. The secret is empty if it's disabled.
+	totpSecret   string
+	totpLastStep int64
+	// hashes of the recovery codes that weren't used yet
+	recoveryCodes []string
+	// when the player was online for the last time, zero if never
+	LastSeen time.Time
 }
 
 func (user *User) SetPassword(password string) (err error) {
-	pwHash, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+	pwHash, err := hashPassword(password)
 	if err != nil {
 		return
 	}
-	user.passwordHash = string(pwHash)
+	user.passwordHash = pwHash
 	return
 }
 
+// ValidatePassword returns true if the password is correct. A hash with outdated parameters is replaced by a new one,
+// and stored if the user exists in the database. Nothing else of the user is stored.
 func (user *User) ValidatePassword(password string) (result bool) {
-	err := bcrypt.CompareHashAndPassword([]byte(user.passwordHash), []byte(password))
+	result, rehash := verifyPassword(user.passwordHash, password)
+	if !result || !rehash {
+		return
+	}
+	oldHash := user.passwordHash
+	newHash, err := hashPassword(password)
 	if err != nil {
-		result = false
-	} else {
-		result = true
+		log.Errorf("Failed to rehash password of user %s: %s", user.Username, err.Error())
+		return
+	}
+	user.passwordHash = newHash
+	if repository == nil {
+		return
+	}
+	replaced, err := repository.ReplacePasswordHash(user.Username, oldHash, newHash)
+	switch {
+	case err != nil:
+		log.Errorf("Failed to rehash password of user %s: %s", user.Username, err.Error())
+	case replaced:
+		log.Infof("Rehashed password of user %s", user.Username)
 	}
 	return
 }
@@ -49,3 +85,52 @@ func (user *User) GetPasswordHash() (result string) {
 	result = user.passwordHash
 	return
 }
+
+// AddAuthorizedKey adds a public key in authorized_keys format (eg: "ssh-ed25519 AAAA... comment"), and saves it.
+// Returns ErrInvalidKey if the key can't be parsed.
+func (user *User) AddAuthorizedKey(line string) (err error) {
+	key, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+	if err != nil {
+		return ErrInvalidKey
+	}
+	if user.IsAuthorizedKey(key) {
+		return
+	}
+	// store in normalized form, without any options
+	normalized := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+	if comment != "" {
+		normalized += " " + comment
+	}
+	// only the key is saved, other changes to the user (eg: in another session) are not overwritten
+	err = repository.AddAuthorizedKey(user.Username, normalized)
+	if err != nil {
+		return
+	}
+	user.keyLock.Lock()
+	defer user.keyLock.Unlock()
+	user.authorizedKeys = append(user.authorizedKeys, normalized)
+	return
+}
+
+func (user *User) IsAuthorizedKey(key ssh.PublicKey) (result bool) {
+	user.keyLock.RLock()
+	defer user.keyLock.RUnlock()
+	for _, line := range user.authorizedKeys {
+		authorizedKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			continue
+		}
+		if bytes.Equal(authorizedKey.Marshal(), key.Marshal()) {
+			result = true
+			break
+		}
+	}
+	return
+}
+
+func (user *User) GetAuthorizedKeys() (result []string) {
+	user.keyLock.RLock()
+	defer user.keyLock.RUnlock()
+	result = append(result, user.authorizedKeys...)
+	return
+}
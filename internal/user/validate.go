@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package user
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jeroenjacobs79/tobw/internal/config"
+)
+
+// Rules for new accounts. The errors are shown to the player, so they explain what's wrong.
+
+const (
+	MinUsernameLength = 3
+	MaxUsernameLength = 25
+	MinHandleLength   = 3
+	MaxHandleLength   = 20
+	MaxEmailLength    = 254
+	MinPasswordLength = 8
+	// bcrypt ignores everything after 72 bytes
+	MaxPasswordLength = 72
+	// long passwords don't need a mix of character classes
+	passphraseLength = 16
+)
+
+// names nobody can use, compared case-insensitively. The anonymous ssh user is reserved too.
+var reservedNames = []string{"new", "sysop", "admin", "root", "guest"}
+
+// ValidateUsername checks the naming rules for login names: letters, digits and single spaces, starting with a letter.
+func ValidateUsername(username string) error {
+	length := utf8.RuneCountInString(username)
+	if length < MinUsernameLength || length > MaxUsernameLength {
+		return fmt.Errorf("Your username must be %d to %d characters long.", MinUsernameLength, MaxUsernameLength)
+	}
+	if strings.TrimSpace(username) != username || strings.Contains(username, "  ") {
+		return fmt.Errorf("Your username can't start or end with a space, or contain two spaces in a row.")
+	}
+	for i, r := range username {
+		if i == 0 && !unicode.IsLetter(r) {
+			return fmt.Errorf("Your username must start with a letter.")
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' {
+			return fmt.Errorf("Your username can only contain letters, digits and spaces.")
+		}
+	}
+	if isReserved(username) {
+		return fmt.Errorf("This username is reserved.")
+	}
+	return nil
+}
+
+// ValidateHandle checks the naming rules for handles. They're shown in the game, so they can contain punctuation.
+func ValidateHandle(handle string) error {
+	length := utf8.RuneCountInString(handle)
+	if length < MinHandleLength || length > MaxHandleLength {
+		return fmt.Errorf("Your handle must be %d to %d characters long.", MinHandleLength, MaxHandleLength)
+	}
+	if strings.TrimSpace(handle) != handle || strings.Contains(handle, "  ") {
+		return fmt.Errorf("Your handle can't start or end with a space, or contain two spaces in a row.")
+	}
+	letters := 0
+	for _, r := range handle {
+		if !unicode.IsPrint(r) {
+			return fmt.Errorf("Your handle contains characters that aren't allowed.")
+		}
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	if letters == 0 {
+		return fmt.Errorf("Your handle must contain at least one letter.")
+	}
+	if isReserved(handle) {
+		return fmt.Errorf("This handle is reserved.")
+	}
+	return nil
+}
+
+// ValidateEmail accepts a plain address, like player@example.com.
+func ValidateEmail(email string) error {
+	if len(email) > MaxEmailLength {
+		return fmt.Errorf("Your email address is too long.")
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || !strings.Contains(email[strings.LastIndex(email, "@")+1:], ".") {
+		return fmt.Errorf("This is not a valid email address.")
+	}
+	return nil
+}
+
+// CheckPasswordStrength rejects passwords that are easy to guess. Shorter passwords need at least three of: lowercase
+// letters, uppercase letters, digits and other characters. The password can't contain the username or handle.
+func CheckPasswordStrength(password string, username string, handle string) error {
+	if utf8.RuneCountInString(password) < MinPasswordLength {
+		return fmt.Errorf("Your password must be at least %d characters long.", MinPasswordLength)
+	}
+	if len(password) > MaxPasswordLength {
+		return fmt.Errorf("Your password can't be longer than %d characters.", MaxPasswordLength)
+	}
+	lowered := strings.ToLower(password)
+	for _, name := range []string{username, handle} {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && strings.Contains(lowered, name) {
+			return fmt.Errorf("Your password can't contain your username or handle.")
+		}
+	}
+	if utf8.RuneCountInString(password) >= passphraseLength {
+		return nil
+	}
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, other} {
+		if present {
+			classes++
+		}
+	}
+	if classes < 3 {
+		return fmt.Errorf("Use at least 3 of: lowercase letters, uppercase letters, digits and symbols. Or use a password of %d characters or more.", passphraseLength)
+	}
+	return nil
+}
+
+func isReserved(name string) bool {
+	if config.AppOptions.SSHAnonymousUser != "" && strings.EqualFold(name, config.AppOptions.SSHAnonymousUser) {
+		return true
+	}
+	for _, reserved := range reservedNames {
+		if strings.EqualFold(name, reserved) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,310 @@
+/*
+ * Copyright (c) 2019 Jeroen Jacobs.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 2 as published by
+ * the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// A minimal WebSocket server (RFC 6455). It only does what our browser terminal needs: binary messages carry the
+// terminal data, text messages carry control messages (eg: resize). The connection works like a net.Conn, so it can
+// be used for an AnsiTerminal just like a telnet or raw tcp connection.
+
+const (
+	opContinuation byte = 0
+	opText         byte = 1
+	opBinary       byte = 2
+	opClose        byte = 8
+	opPing         byte = 9
+	opPong         byte = 10
+
+	finBit  byte = 0x80
+	maskBit byte = 0x80
+
+	// magic value from the RFC, used to calculate Sec-WebSocket-Accept
+	acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	// maximum payload of a single frame we accept, terminal input is small
+	maxFrameSize = 64 * 1024
+	// maximum payload of control frames, set by the RFC
+	maxControlFrameSize = 125
+	// maximum size of a complete text message
+	maxTextMessageSize = 4096
+
+	closeNormal          = 1000
+	closeProtocolError   = 1002
+	closeMessageTooLarge = 1009
+)
+
+var (
+	ErrProtocol = errors.New("websocket: protocol error")
+	ErrTooLarge = errors.New("websocket: message too large")
+)
+
+type Conn struct {
+	net.Conn
+	in          *bufio.Reader
+	writeLock   sync.Mutex
+	closeOnce   sync.Once
+	closeSent   bool
+	textHandler func([]byte)
+	// data of the current binary frame we didn't return yet
+	remaining []byte
+	// type of the fragmented message we're receiving
+	messageType byte
+	textMessage []byte
+}
+
+func isToken(header http.Header, name string, token string) bool {
+	for _, value := range header[http.CanonicalHeaderKey(name)] {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// Upgrade does the opening handshake and takes over the connection of the request.
+// On failure, an error response has been sent to the client already.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, fmt.Errorf("websocket: invalid method %s", r.Method)
+	}
+	if !isToken(r.Header, "Connection", "upgrade") || !isToken(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "websocket upgrade required", http.StatusBadRequest)
+		return nil, errors.New("websocket: not a websocket upgrade request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing websocket key", http.StatusBadRequest)
+		return nil, errors.New("websocket: missing key")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket not supported", http.StatusInternalServerError)
+		return nil, errors.New("websocket: connection can't be hijacked")
+	}
+	netConn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	_, err = netConn.Write([]byte(response))
+	if err != nil {
+		_ = netConn.Close()
+		return nil, err
+	}
+	// the buffered reader can contain data the client sent after the handshake
+	return &Conn{Conn: netConn, in: rw.Reader}, nil
+}
+
+// InstallTextHandler sets the function that receives the text messages of the client.
+func (c *Conn) InstallTextHandler(handler func([]byte)) {
+	c.textHandler = handler
+}
+
+// Read returns the data of the binary messages. Text messages are passed to the text handler.
+func (c *Conn) Read(data []byte) (int, error) {
+	for len(c.remaining) == 0 {
+		err := c.readFrame()
+		if err != nil {
+			return 0, err
+		}
+	}
+	n := copy(data, c.remaining)
+	c.remaining = c.remaining[n:]
+	return n, nil
+}
+
+func (c *Conn) readFrame() error {
+	var header [2]byte
+	_, err := io.ReadFull(c.in, header[:])
+	if err != nil {
+		return err
+	}
+	fin := header[0]&finBit != 0
+	opcode := header[0] & 0x0f
+	// clients must mask every frame
+	if header[1]&maskBit == 0 || header[0]&0x70 != 0 {
+		return c.fail(closeProtocolError, ErrProtocol)
+	}
+	length := uint64(header[1] &^ maskBit)
+	switch length {
+	case 126:
+		var extended [2]byte
+		_, err = io.ReadFull(c.in, extended[:])
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		_, err = io.ReadFull(c.in, extended[:])
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if err != nil {
+		return err
+	}
+	isControl := opcode&0x08 != 0
+	if (isControl && (length > maxControlFrameSize || !fin)) || length > maxFrameSize {
+		return c.fail(closeMessageTooLarge, ErrTooLarge)
+	}
+	var mask [4]byte
+	_, err = io.ReadFull(c.in, mask[:])
+	if err != nil {
+		return err
+	}
+	payload := make([]byte, length)
+	_, err = io.ReadFull(c.in, payload)
+	if err != nil {
+		return err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	switch opcode {
+	case opPing:
+		return c.writeFrame(opPong, payload)
+	case opPong:
+		return nil
+	case opClose:
+		// answer with the same status code, then we're done
+		_ = c.sendClose(payload)
+		return io.EOF
+	case opText, opBinary:
+		if c.messageType != 0 {
+			return c.fail(closeProtocolError, ErrProtocol)
+		}
+		c.messageType = opcode
+	case opContinuation:
+		if c.messageType == 0 {
+			return c.fail(closeProtocolError, ErrProtocol)
+		}
+	default:
+		return c.fail(closeProtocolError, ErrProtocol)
+	}
+
+	messageType := c.messageType
+	if fin {
+		c.messageType = 0
+	}
+	if messageType == opBinary {
+		c.remaining = payload
+		return nil
+	}
+	c.textMessage = append(c.textMessage, payload...)
+	if len(c.textMessage) > maxTextMessageSize {
+		return c.fail(closeMessageTooLarge, ErrTooLarge)
+	}
+	if fin {
+		message := c.textMessage
+		c.textMessage = nil
+		if c.textHandler != nil {
+			c.textHandler(message)
+		}
+	}
+	return nil
+}
+
+// sends a close frame with the status code and returns the error
+func (c *Conn) fail(code uint16, err error) error {
+	var payload [2]byte
+	binary.BigEndian.PutUint16(payload[:], code)
+	_ = c.sendClose(payload[:])
+	return err
+}
+
+// Write sends the data as a single binary message.
+func (c *Conn) Write(data []byte) (int, error) {
+	err := c.writeFrame(opBinary, data)
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	if c.closeSent {
+		return io.ErrClosedPipe
+	}
+	// servers never mask their frames
+	header := []byte{finBit | opcode, 0}
+	length := len(payload)
+	switch {
+	case length < 126:
+		header[1] = byte(length)
+	case length <= 0xffff:
+		header[1] = 126
+		header = append(header, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header[1] = 127
+		header = append(header, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+	_, err := c.Conn.Write(append(header, payload...))
+	if opcode == opClose {
+		c.closeSent = true
+	}
+	return err
+}
+
+func (c *Conn) sendClose(payload []byte) error {
+	return c.writeFrame(opClose, payload)
+}
+
+// Close sends a close frame before closing the connection. It's safe to call this more than once.
+func (c *Conn) Close() (err error) {
+	c.closeOnce.Do(func() {
+		var payload [2]byte
+		binary.BigEndian.PutUint16(payload[:], closeNormal)
+		sendErr := c.sendClose(payload[:])
+		if sendErr != nil && sendErr != io.ErrClosedPipe {
+			log.Tracef("%s - Failed to send websocket close frame: %s", c.RemoteAddr(), sendErr.Error())
+		}
+		err = c.Conn.Close()
+	})
+	return
+}
@@ -17,18 +17,25 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 
 	"github.com/jeroenjacobs79/tobw/internal/monitoring"
 
 	"github.com/jeroenjacobs79/tobw/internal/config"
+	"github.com/jeroenjacobs79/tobw/internal/guard"
+	"github.com/jeroenjacobs79/tobw/internal/hostkey"
+	"github.com/jeroenjacobs79/tobw/internal/mail"
 	"github.com/jeroenjacobs79/tobw/internal/termserve"
+	"github.com/jeroenjacobs79/tobw/internal/user"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
 )
 
 // will be replaced during build-phase with actual git-based version info
@@ -48,29 +55,46 @@ func main() {
 	}
 }
 
+// The first signal starts a graceful shutdown, a second one exits immediately.
 func cancelOnInterrupt(ctx context.Context, cancelFunction context.CancelFunc) {
 	term := make(chan os.Signal, 1)
 	signal.Notify(term, os.Interrupt, syscall.SIGTERM)
 
-	for {
-		select {
-		case sig := <-term:
-			log.Infof("Received %s, exiting gracefully...", sig)
-			cancelFunction()
-			os.Exit(0)
-		case <-ctx.Done():
-			os.Exit(0)
-		}
+	select {
+	case sig := <-term:
+		log.Infof("Received %s, exiting gracefully...", sig)
+		cancelFunction()
+	case <-ctx.Done():
+		return
 	}
+	sig := <-term
+	log.Infof("Received %s again, exiting immediately.", sig)
+	os.Exit(1)
 }
 
 func run(ctx context.Context) error {
+	// generate the ssh host keys, without starting the server
+	if len(os.Args) == 3 && os.Args[1] == "keygen" {
+		return keygen(os.Args[2])
+	}
+	// create a user account
+	if len(os.Args) == 4 && os.Args[1] == "adduser" {
+		return adduser(os.Args[2], os.Args[3])
+	}
+	// manage the ban list
+	if len(os.Args) >= 3 && (os.Args[1] == "ban" || os.Args[1] == "unban") {
+		return banCommand(os.Args[1], os.Args[2], os.Args[3:])
+	}
 	// parse commandline for config file. Error if not specified.
 	if len(os.Args) != 2 {
 		fmt.Printf("%s (version %s)\n", AppName, Version)
 		fmt.Println("No config file specified.")
 		fmt.Println()
 		fmt.Println("Usage:", os.Args[0], "/path/to/config.yaml")
+		fmt.Println("      ", os.Args[0], "keygen /path/to/config.yaml")
+		fmt.Println("      ", os.Args[0], "adduser /path/to/config.yaml username  (reads the password from stdin)")
+		fmt.Println("      ", os.Args[0], "ban /path/to/config.yaml [address[/bits] [reason]]")
+		fmt.Println("      ", os.Args[0], "unban /path/to/config.yaml address[/bits]")
 		return nil
 	}
 
@@ -88,6 +112,23 @@ func run(ctx context.Context) error {
 	log.SetLevel(config.AppOptions.LogLevel)
 	// startup message
 	log.Infof("%s (version %s) is starting up...", AppName, Version)
+	// connect to the user database
+	err = user.Open(config.AppOptions.Database)
+	if err != nil {
+		return err
+	}
+	defer user.Close()
+	user.SetHasher(user.NewHasher(config.AppOptions.Passwords))
+	// bans are checked before sessions start
+	err = guard.LoadBans(config.AppOptions.BanList)
+	if err != nil {
+		return err
+	}
+	// set up the mailer for verification codes
+	err = mail.Open(config.AppOptions.Mail)
+	if err != nil {
+		return err
+	}
 	// start metrics endpoint, if configured
 	if config.AppOptions.Prometheus.Enabled {
 		go monitoring.StartMetricsEndpoint(config.AppOptions.Prometheus)
@@ -96,8 +137,112 @@ func run(ctx context.Context) error {
 	var wg sync.WaitGroup
 	for _, listener := range listeners {
 		wg.Add(1)
-		go termserve.StartListener(&wg, fmt.Sprintf("%s:%d", listener.Address, listener.Port), listener.ListenType, listener.ConvertUTF8)
+		go termserve.StartListener(ctx, &wg, listener)
 	}
+	// listeners only stop when we shut down, or when they fail to start
 	wg.Wait()
+	// let the players finish
+	termserve.Shutdown(config.AppOptions.ShutdownDrain)
+	log.Infof("%s has stopped.", AppName)
+	if ctx.Err() == nil {
+		// we weren't asked to stop, so every listener failed. Exit with an error, so it gets restarted.
+		return fmt.Errorf("No listener is running")
+	}
+	return ctx.Err()
+}
+
+// creates the ssh host keys that don't exist yet, and shows the fingerprints of all keys.
+func keygen(configFile string) error {
+	_, err := config.ParseConfig(configFile)
+	if err != nil {
+		return err
+	}
+	created, err := hostkey.Generate(config.AppOptions.SSHHostKeyDir)
+	if err != nil {
+		return err
+	}
+	if len(created) == 0 {
+		fmt.Println("All ssh host keys already exist in", config.AppOptions.SSHHostKeyDir)
+	}
+	for _, path := range created {
+		fmt.Println("Created", path)
+	}
+	hostKeys, err := hostkey.Load(config.AppOptions.SSHHostKeyDir)
+	if err != nil {
+		return err
+	}
+	for _, hostKey := range hostKeys {
+		fmt.Println(hostKey.PublicKey().Type(), ssh.FingerprintSHA256(hostKey.PublicKey()))
+	}
+	return nil
+}
+
+// creates a user account, with the password read from stdin.
+func adduser(configFile string, username string) error {
+	_, err := config.ParseConfig(configFile)
+	if err != nil {
+		return err
+	}
+	err = user.Open(config.AppOptions.Database)
+	if err != nil {
+		return err
+	}
+	defer user.Close()
+	user.SetHasher(user.NewHasher(config.AppOptions.Passwords))
+	fmt.Print("Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		return fmt.Errorf("The password can't be empty")
+	}
+	newUser := &user.User{
+		Username: username,
+	}
+	err = newUser.SetPassword(password)
+	if err != nil {
+		return err
+	}
+	err = user.Register(newUser)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Created user", newUser.Username)
 	return nil
 }
+
+// adds an address or range to the ban list, or removes it. Without address, the ban list is shown.
+// The running server picks up the changes.
+func banCommand(command string, configFile string, args []string) error {
+	_, err := config.ParseConfig(configFile)
+	if err != nil {
+		return err
+	}
+	err = guard.LoadBans(config.AppOptions.BanList)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		if command == "unban" {
+			return fmt.Errorf("No address specified")
+		}
+		for _, line := range guard.Bans() {
+			fmt.Println(line)
+		}
+		return nil
+	}
+	if command == "unban" {
+		err = guard.Unban(args[0])
+		if err == nil {
+			fmt.Println("Unbanned", args[0])
+		}
+		return err
+	}
+	err = guard.Ban(args[0], strings.Join(args[1:], " "))
+	if err == nil {
+		fmt.Println("Banned", args[0])
+	}
+	return err
+}